@@ -20,6 +20,8 @@ import (
 
 	"github.com/kodekulture/wordle-server/handler"
 	"github.com/kodekulture/wordle-server/handler/token"
+	"github.com/kodekulture/wordle-server/repository"
+	"github.com/kodekulture/wordle-server/repository/file"
 	"github.com/kodekulture/wordle-server/repository/postgres"
 	"github.com/kodekulture/wordle-server/repository/redis"
 	"github.com/kodekulture/wordle-server/service"
@@ -48,7 +50,12 @@ func main() {
 		log.Fatal(err)
 	}
 
-	srv := service.New(appCtx, postgres.NewGameRepo(db), postgres.NewPlayerRepo(db), redis.NewGameRepo(cl))
+	wal, err := getLog(cl)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := service.New(appCtx, postgres.NewGameRepo(db), postgres.NewPlayerRepo(db), postgres.NewMatchRepo(db), postgres.NewDailyRepo(db), redis.NewGameRepo(cl), redis.NewInviteRepo(cl), wal)
 
 	tokener, err := token.New([]byte(config.Get("PASETO_KEY")), "")
 	if err != nil {
@@ -89,6 +96,16 @@ func getRedis(ctx context.Context) (*redis9.Client, error) {
 	return cl, nil
 }
 
+// getLog returns the log of the games, it is stored in the files of LOG_DIR when it is set and in redis otherwise.
+// The files of LOG_DIR are not shared between instances, they are only used by single instance deployments.
+func getLog(cl *redis9.Client) (repository.Log, error) {
+	dir := config.Get("LOG_DIR")
+	if dir == "" {
+		return redis.NewLogRepo(cl), nil
+	}
+	return file.NewLog(dir)
+}
+
 func shutdown(s *handler.Handler, done chan<- struct{}) {
 	// Wait for interrupt signal to gracefully shutdown the server with
 	sig := make(chan os.Signal, 1)
@@ -0,0 +1,31 @@
+// Command schema generates the JSON Schema of the messages of the live socket from the types of package game.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/kodekulture/wordle-server/game"
+)
+
+func main() {
+	out := flag.String("o", "", "file the schema is written to, it is written to stdout when empty")
+	flag.Parse()
+
+	b, err := json.MarshalIndent(game.Schema(), "", "  ")
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to encode schema")
+	}
+	b = append(b, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(b)
+	} else {
+		err = os.WriteFile(*out, b, 0o644)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to write schema")
+	}
+}
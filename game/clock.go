@@ -0,0 +1,46 @@
+package game
+
+import "time"
+
+// tickInterval is how often players are notified about the time left in the game
+var tickInterval = 10 * time.Second
+
+// clock keeps track of the time left in a game that has started.
+type clock struct {
+	deadline time.Time
+	ticker   *time.Ticker // ticker notifies the room when players should be reminded about the time left
+	timer    *time.Timer  // timer notifies the room when the game has reached its time limit
+}
+
+// newClock starts a clock that runs until the deadline.
+// If the deadline has passed already, the timer of the clock fires immediately.
+func newClock(deadline time.Time) *clock {
+	return &clock{
+		deadline: deadline,
+		ticker:   time.NewTicker(tickInterval),
+		timer:    time.NewTimer(time.Until(deadline)),
+	}
+}
+
+// channels returns the channels of the ticker and the timer of the clock.
+// It returns nil channels, which block forever, when the clock is nil.
+func (c *clock) channels() (tick, timeUp <-chan time.Time) {
+	if c == nil {
+		return nil, nil
+	}
+	return c.ticker.C, c.timer.C
+}
+
+// remaining returns the time left before the deadline rounded to the second.
+func (c *clock) remaining() time.Duration {
+	return max(time.Until(c.deadline).Round(time.Second), 0)
+}
+
+// stop stops the clock, no more ticks are sent after it returns.
+func (c *clock) stop() {
+	if c == nil {
+		return
+	}
+	c.ticker.Stop()
+	c.timer.Stop()
+}
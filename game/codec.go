@@ -0,0 +1,97 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec encodes the messages of the live socket, it is negotiated with the client through a websocket subprotocol.
+type Codec interface {
+	// Subprotocol is the websocket subprotocol asked by clients to use the codec
+	Subprotocol() string
+	// MessageType is the type of the websocket messages written with the codec
+	MessageType() int
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSON encodes messages in text frames, it is used by clients that do not ask for a subprotocol.
+	JSON Codec = jsonCodec{}
+	// MessagePack encodes messages in binary frames with the same field names as JSON.
+	MessagePack Codec = msgpackCodec{}
+
+	// Codecs contains the codecs supported by the server in order of preference.
+	Codecs = []Codec{JSON, MessagePack}
+)
+
+// Subprotocols returns the websocket subprotocols of the supported codecs.
+func Subprotocols() []string {
+	res := make([]string, len(Codecs))
+	for i, c := range Codecs {
+		res[i] = c.Subprotocol()
+	}
+	return res
+}
+
+// CodecFor returns the codec of the negotiated websocket `subprotocol`, JSON is used when no subprotocol was negotiated.
+func CodecFor(subprotocol string) Codec {
+	for _, c := range Codecs {
+		if c.Subprotocol() == subprotocol {
+			return c
+		}
+	}
+	return JSON
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Subprotocol() string                { return "json" }
+func (jsonCodec) MessageType() int                   { return websocket.TextMessage }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Subprotocol() string { return "msgpack" }
+func (msgpackCodec) MessageType() int    { return websocket.BinaryMessage }
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// rawData is the data of a message left encoded by the codec of the message, it is decoded once its event is known.
+type rawData []byte
+
+func (d *rawData) UnmarshalJSON(b []byte) error {
+	*d = append((*d)[:0], b...)
+	return nil
+}
+
+func (d *rawData) DecodeMsgpack(dec *msgpack.Decoder) error {
+	b, err := dec.DecodeRaw()
+	*d = rawData(b)
+	return err
+}
+
+// request is a message sent by a player.
+type request struct {
+	Type Event   `json:"event"`
+	Data rawData `json:"data"`
+	Key  string  `json:"key,omitempty"`
+}
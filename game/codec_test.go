@@ -0,0 +1,121 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lordvidex/x/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecFor(t *testing.T) {
+	assert.Equal(t, JSON, CodecFor(""))
+	assert.Equal(t, JSON, CodecFor("json"))
+	assert.Equal(t, MessagePack, CodecFor("msgpack"))
+	assert.Equal(t, []string{"json", "msgpack"}, Subprotocols())
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 42, time.UTC)
+	guess := GuessResponse{Word: ptr.String("GAMES"), PlayedAt: now, Status: []int{1, 2, 3, 2, 1}}
+	leaderboard := LeaderboardResponse{{Rank: 1, Best: GuessResponse{PlayedAt: now, Status: []int{3, 3, 3, 3, 3}}, Username: "fela", WordsPlayed: 2}}
+	match := MatchResponse{
+		ID: uuid.New(), Round: 2, Rounds: 3, CreatedAt: now,
+		Leaderboard: []MatchScoreResponse{{Rank: 0, Username: "fela", Points: 4}},
+		History:     []RoundResponse{{Number: 1, GameID: uuid.New(), CorrectWord: "GAMES", EndedAt: now}},
+	}
+	lobby := LobbyResponse{Players: []LobbyPlayerResponse{{Username: "fela", Ready: true}}, Countdown: ptr.Obj(3)}
+
+	requestData := map[Event]any{
+		SMessage:  MessageRequest("hello"),
+		SPlay:     PlayRequest("GAMES"),
+		SStart:    StartRequest{},
+		SReady:    ReadyRequest(false),
+		SSync:     SyncRequest(42),
+		SKick:     ModerationRequest("james"),
+		SBan:      ModerationRequest("james"),
+		STransfer: ModerationRequest("james"),
+
+		SLeaderboard: LeaderboardRequest{},
+	}
+	responseData := map[Event]any{
+		CMessage:      "hello",
+		CPlay:         PlayerGuessResponse{Result: guess, RankOffset: ptr.Obj(1), Leaderboard: leaderboard, Version: 3},
+		CFinish:       "fela won",
+		CRound:        match,
+		CTick:         TickResponse{Remaining: 30},
+		CStart:        "game started",
+		CLobby:        lobby,
+		CJoin:         "fela joined",
+		CLeave:        "fela left",
+		CReconnecting: "fela is reconnecting",
+		CSpectators:   SpectatorsResponse{Count: 2},
+		CModeration:   ModerationResponse{Action: ActionBan, Moderator: "fela", Target: "james"},
+		COwner:        OwnerResponse{Creator: "james"},
+		CLeaderboard:  LeaderboardSnapshotResponse{Version: 4, Leaderboard: leaderboard},
+		CData: InitialData{
+			Response: Response{
+				CreatedAt: now, StartedAt: &now, Creator: "fela", Guesses: []GuessResponse{guess},
+				GamePerformance: leaderboard, Settings: GameSettings{}, ID: uuid.New(),
+			},
+			Active: true, Match: &match, Spectators: 1, Lobby: &lobby, ResumeToken: "token",
+			Reconnecting: []string{"james"}, Seq: 7, Version: ProtocolVersion, LeaderboardVersion: 3,
+		},
+		CError: ErrorResponse{
+			Code: ErrCodeHintNotUsed, Message: "hint",
+			Hint: &HintErrorResponse{Message: "hint", Constraint: "keep_correct", Letter: "G", Position: ptr.Obj(1)},
+		},
+	}
+	require.Len(t, requestData, len(requests), "every request has data")
+	require.Len(t, responseData, len(responses), "every response has data")
+
+	for _, c := range Codecs {
+		for event, data := range requestData {
+			b, err := c.Marshal(Payload{Type: event, Data: data, Key: "k1"})
+			require.NoError(t, err, "%s %s", c.Subprotocol(), event)
+			var req request
+			require.NoError(t, c.Unmarshal(b, &req), "%s %s", c.Subprotocol(), event)
+			assert.Equal(t, event, req.Type)
+			assert.Equal(t, "k1", req.Key)
+			got, err := decodeRequest(c, req.Type, req.Data)
+			require.NoError(t, err, "%s %s", c.Subprotocol(), event)
+			assert.Equal(t, data, got, "%s %s", c.Subprotocol(), event)
+		}
+		for event, data := range responseData {
+			b, err := c.Marshal(Payload{Type: event, Data: data, From: "fela", Key: "k1", Seq: 3})
+			require.NoError(t, err, "%s %s", c.Subprotocol(), event)
+			var got struct {
+				Type Event   `json:"event"`
+				Data rawData `json:"data"`
+				From string  `json:"from"`
+				Key  string  `json:"key"`
+				Seq  uint64  `json:"seq"`
+			}
+			require.NoError(t, c.Unmarshal(b, &got), "%s %s", c.Subprotocol(), event)
+			v := reflect.New(responses[event])
+			require.NoError(t, c.Unmarshal(got.Data, v.Interface()), "%s %s", c.Subprotocol(), event)
+			// times are decoded in another location, the decoded payload must be encoded as the original one
+			decoded := Payload{Type: got.Type, Data: v.Elem().Interface(), From: got.From, Key: got.Key, Seq: got.Seq}
+			b2, err := c.Marshal(decoded)
+			require.NoError(t, err, "%s %s", c.Subprotocol(), event)
+			assert.Equal(t, b, b2, "%s %s", c.Subprotocol(), event)
+		}
+	}
+}
+
+func TestDecodeRequest_MessagePack(t *testing.T) {
+	for _, data := range []any{nil, true} {
+		b, err := MessagePack.Marshal(data)
+		require.NoError(t, err)
+		got, err := decodeRequest(MessagePack, SReady, b)
+		require.NoError(t, err)
+		assert.Equal(t, ReadyRequest(true), got)
+	}
+	b, err := MessagePack.Marshal(42)
+	require.NoError(t, err)
+	_, err = decodeRequest(MessagePack, SPlay, b)
+	assert.Error(t, err)
+}
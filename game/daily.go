@@ -0,0 +1,45 @@
+package game
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+// dailyNamespace is used to derive the ID of the daily game from its day
+var dailyNamespace = uuid.MustParse("1b0e5a6f-3c3f-4a8e-9f5e-2d7c1c6e8a41")
+
+// Day returns the start of the UTC day of t.
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// NewDaily creates the game of the daily challenge of `day` played by `sessions`, a player plays the daily game
+// when their session is included.
+// The daily game starts with the day and only ends when the day is over, the correct word is not revealed before.
+// Players are ranked like in Wizard mode.
+func NewDaily(day time.Time, correctWord word.Word, sessions []*Session) *Game {
+	day = Day(day)
+	g := &Game{
+		ID:          uuid.NewSHA1(dailyNamespace, []byte(day.Format(time.DateOnly))),
+		CreatedAt:   day,
+		CorrectWord: correctWord,
+		Settings:    DefaultSettings(),
+		Sessions:    make(map[string]*Session, len(sessions)),
+		daily:       true,
+	}
+	g.Settings.Mode = Wizard
+	for _, s := range sessions {
+		g.Sessions[s.Player.Username] = s
+	}
+	g.StartedAt = &day
+	g.Leaderboard = NewRankBoard(g.Sessions)
+	g.Resync()
+	if end := day.AddDate(0, 0, 1); !time.Now().Before(end) {
+		g.EndedAt = &end
+	}
+	return g
+}
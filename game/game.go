@@ -3,7 +3,9 @@ package game
 import (
 	"encoding/json"
 	"errors"
-	"sort"
+	"maps"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,26 +20,40 @@ var (
 )
 
 const (
+	// MinDuration is the minimum duration a game can last or wait to be started
+	MinDuration = time.Minute
+
 	// MaxDuration is the maximum duration a game can last
 	MaxDuration = time.Hour
 
-	// MaxGuesses is the maximum number of guesses a player can make
-	MaxGuesses = 6
+	// MaxLobbyDuration is the maximum duration a game can wait to be started
+	MaxLobbyDuration = time.Hour
+
+	// MaxGuesses is the maximum number of guesses a player can be allowed to make
+	MaxGuesses = 10
+
+	// DefaultGuesses is the number of guesses a player can make when the creator of the game does not choose it
+	DefaultGuesses = 6
+
+	// MaxCountdown is the maximum duration a game can wait to be started once a player is ready
+	MaxCountdown = 5 * time.Minute
 )
 
 type RankBoard struct {
 	Positions map[string]int
 	Ranks     []*Session
+
+	// cmp determines the order of the sessions, ByClassic is used when it is not set.
+	cmp SessionComparator
 }
 
+// NewRankBoard returns a board of the sessions ordered by the username of their player, the board is not sorted.
 func NewRankBoard(initial map[string]*Session) RankBoard {
 	ranks := make([]*Session, 0, len(initial))
 	positions := make(map[string]int)
-	var index int
-	for username, session := range initial {
-		ranks = append(ranks, session)
-		positions[username] = index
-		index++
+	for _, username := range slices.Sorted(maps.Keys(initial)) {
+		positions[username] = len(ranks)
+		ranks = append(ranks, initial[username])
 	}
 	return RankBoard{
 		Ranks:     ranks,
@@ -45,11 +61,17 @@ func NewRankBoard(initial map[string]*Session) RankBoard {
 	}
 }
 
-// Resync ...
+// compare compares s1 and s2 with the comparator of this board.
+func (r RankBoard) compare(s1, s2 *Session) int {
+	if r.cmp == nil {
+		return ByClassic(s1, s2)
+	}
+	return r.cmp(s1, s2)
+}
+
+// Resync sorts the sessions on the board, sessions that can not be told apart keep their current order.
 func (r RankBoard) Resync() {
-	sort.Slice(r.Ranks, func(i, j int) bool {
-		return r.Ranks[i].BestGuess().GreaterThan(r.Ranks[j].BestGuess())
-	})
+	slices.SortStableFunc(r.Ranks, r.compare)
 
 	for i, v := range r.Ranks {
 		r.Positions[v.Player.Username] = i
@@ -64,8 +86,7 @@ func (r RankBoard) FixPosition(username string) int {
 	index := r.Positions[username]
 	for i := index; i > 0; i-- {
 		curr, prev := r.Ranks[i], r.Ranks[i-1]
-		// TODO: add other comparators in else if
-		if curr.GreaterThan(prev) {
+		if r.compare(curr, prev) < 0 {
 			r.Ranks[i-1], r.Ranks[i] = curr, prev
 			r.Positions[curr.Player.Username] = i - 1
 			r.Positions[prev.Player.Username] = i
@@ -90,19 +111,34 @@ type Game struct {
 	EndedAt     *time.Time
 	Creator     string
 	CorrectWord word.Word
+	Settings    GameSettings
 	finished    int
-	ID          uuid.UUID
+	// played is the number of guesses made in the game, every guess changes the leaderboard
+	played int
+	// daily is true for the game of a daily challenge, it does not end when its players finish
+	daily bool
+	ID    uuid.UUID
 }
 
 func (g *Game) Start() {
-	now := time.Now()
+	g.startAt(time.Now())
+}
+
+// startAt starts the game at `now`.
+func (g *Game) startAt(now time.Time) {
 	g.StartedAt = &now
 	g.Leaderboard = NewRankBoard(g.Sessions)
+	g.Leaderboard.cmp = g.Settings.comparator()
 }
 
 // Join is used to enter a game before it starts
 func (g *Game) Join(p Player) {
-	g.Sessions[p.Username] = &Session{Player: p}
+	g.Sessions[p.Username] = &Session{Player: p, maxGuesses: g.Rules().MaxGuesses}
+}
+
+// Leave removes the session of a player, it is used to leave a game before it starts
+func (g *Game) Leave(username string) {
+	delete(g.Sessions, username)
 }
 
 // IsActive returns true if game has started, otherwise false
@@ -110,6 +146,50 @@ func (g Game) IsActive() bool {
 	return g.StartedAt != nil
 }
 
+// Deadline returns the time at which the game ends if players have not finished yet.
+// It returns the zero time if the game has not started.
+func (g *Game) Deadline() time.Time {
+	if g.StartedAt == nil {
+		return time.Time{}
+	}
+	return g.StartedAt.Add(g.Rules().Duration())
+}
+
+// Expire ends the game when its time limit is reached.
+// The sessions of players who are still playing are marked as failed.
+func (g *Game) Expire() {
+	g.expireAt(time.Now())
+}
+
+// expireAt ends the game at `now` unless its deadline was reached before.
+func (g *Game) expireAt(now time.Time) {
+	if g.HasEnded() {
+		return
+	}
+	for _, session := range g.Sessions {
+		if !session.Ended() {
+			session.expired = true
+		}
+	}
+	g.finished = len(g.Sessions)
+	endedAt := g.Deadline()
+	if g.StartedAt == nil || now.Before(endedAt) {
+		endedAt = now
+	}
+	g.EndedAt = &endedAt
+}
+
+// Rules returns the settings this game is played with.
+// Unlike Settings, every rule is set, even for games stored before the rule could be chosen.
+func (g *Game) Rules() GameSettings {
+	return g.Settings.withDefaults()
+}
+
+// WordLength returns the length of the words played in this game.
+func (g *Game) WordLength() int {
+	return g.Rules().WordLength
+}
+
 // HasEnded returns true if game has ended, otherwise false
 func (g *Game) HasEnded() bool {
 	return g.EndedAt != nil
@@ -122,6 +202,7 @@ func New(creator string, correctWord word.Word) *Game {
 		CreatedAt:   time.Now(),
 		CorrectWord: correctWord,
 		Creator:     creator,
+		Settings:    DefaultSettings(),
 		Sessions:    make(map[string]*Session),
 	}
 }
@@ -131,6 +212,11 @@ func New(creator string, correctWord word.Word) *Game {
 //
 // Play also sets the EndTime of the game if the game has ended for every player.
 func (g *Game) Play(player string, guess *word.Word) (int, bool, error) {
+	return g.playAt(player, guess, time.Now().UTC())
+}
+
+// playAt plays a guess at `now`, the outcome of a guess only depends on the state of the game and `now`.
+func (g *Game) playAt(player string, guess *word.Word, now time.Time) (int, bool, error) {
 	session := g.Sessions[player]
 	if session == nil {
 		return 0, false, ErrPlayerNotFound
@@ -139,32 +225,132 @@ func (g *Game) Play(player string, guess *word.Word) (int, bool, error) {
 	if session.Ended() { // game has ended, no need to add more guesses
 		return 0, false, ErrSessionEnded
 	}
+	if g.Rules().HardMode {
+		if err := guess.UsesHints(session.Guesses); err != nil {
+			return 0, false, err
+		}
+	}
 	// process the guess
-	guess.PlayedAt.Scan(time.Now().UTC())
+	guess.PlayedAt.Scan(now)
 	guess.Check(g.CorrectWord)
 	usersBest := session.play(ptr.ToObj(guess))
+	g.played++
 
 	// update the leaderboard
 	offset := g.Leaderboard.FixPosition(session.Player.Username)
 	if session.Ended() {
 		g.finished++
-		if g.finished == len(g.Sessions) {
-			now := time.Now()
+		if g.finished == len(g.Sessions) && !g.daily {
 			g.EndedAt = &now // game is over when everyone has finished guessing the word or have failed to guess the word
 		}
 	}
 	return offset, usersBest, nil
 }
 
-// Resync ...
+// Resync recomputes the sessions and the leaderboard of a game restored from storage.
 func (g *Game) Resync() {
+	g.resyncSessions()
+	g.Leaderboard.cmp = g.Settings.comparator()
+	g.Leaderboard.Resync()
+}
+
+// Restore recomputes the sessions of a game restored from storage like Resync, the leaderboard keeps the stored order of its players.
+// `ranks` lists the players from the highest rank, the players missing from it rank after them.
+// The leaderboard is only sorted when the stored order does not match the sessions.
+func (g *Game) Restore(ranks []string) {
+	g.resyncSessions()
+	board := RankBoard{
+		Ranks:     make([]*Session, 0, len(g.Sessions)),
+		Positions: make(map[string]int, len(g.Sessions)),
+		cmp:       g.Settings.comparator(),
+	}
+	for _, username := range ranks {
+		if s, ok := g.Sessions[username]; ok {
+			board.Positions[username] = len(board.Ranks)
+			board.Ranks = append(board.Ranks, s)
+		}
+	}
+	missing := make([]string, 0, len(g.Sessions)-len(board.Ranks))
+	for username := range g.Sessions {
+		if _, ok := board.Positions[username]; !ok {
+			missing = append(missing, username)
+		}
+	}
+	slices.Sort(missing)
+	for _, username := range missing {
+		board.Positions[username] = len(board.Ranks)
+		board.Ranks = append(board.Ranks, g.Sessions[username])
+	}
+	if !slices.IsSortedFunc(board.Ranks, board.compare) {
+		board.Resync()
+	}
+	g.Leaderboard = board
+}
+
+// resyncSessions recomputes the sessions of a game and the number of guesses made.
+func (g *Game) resyncSessions() {
+	g.finished, g.played = 0, 0
 	for _, session := range g.Sessions {
+		session.maxGuesses = g.Rules().MaxGuesses
 		session.Resync()
-		if session.Won() {
+		g.played += len(session.Guesses)
+		if session.Ended() {
 			g.finished++
 		}
 	}
-	g.Leaderboard.Resync()
+}
+
+// maxPlayTime is the largest time of a guess in microseconds since the start of the game kept in a score.
+const maxPlayTime = 1<<40 - 1
+
+// Score returns the rank of the session of a player as a number, sessions with a lower score rank higher on the leaderboard.
+// Storages keep the leaderboard of a game in order with it, Restore sorts the leaderboard again in the rare cases where
+// the comparator of the game tells apart sessions with the same score.
+func (g *Game) Score(username string) float64 {
+	s, ok := g.Sessions[username]
+	if !ok {
+		return math.MaxFloat64
+	}
+	best := s.BestGuess()
+	correct, exist := best.Letters()
+	var lost, count uint64
+	if mode := g.Rules().Mode; mode == Sprint || mode == Wizard {
+		if !s.Won() {
+			lost = 1
+		} else if mode == Wizard {
+			count = uint64(min(s.WordsCount(), 15))
+		}
+	}
+	elapsed := uint64(maxPlayTime)
+	if best.PlayedAt.Valid && g.StartedAt != nil {
+		elapsed = uint64(min(max(best.PlayedAt.Time.Sub(*g.StartedAt).Microseconds(), 0), maxPlayTime))
+	}
+	// every part of the score fits in 53 bits, the score is an exact float64
+	score := lost<<52 | count<<48 | uint64(15-min(correct, 15))<<44 | uint64(15-min(exist, 15))<<40 | elapsed
+	return float64(score)
+}
+
+// LeaderboardVersion returns the version of the leaderboard, it increases with every guess made in the game.
+func (g *Game) LeaderboardVersion() int {
+	return g.played
+}
+
+// Clone returns a deep copy of the game, the sessions of the copy can be read while the game is played.
+func (g *Game) Clone() *Game {
+	cp := *g
+	sessions := make(map[string]*Session, len(g.Sessions))
+	for username, s := range g.Sessions {
+		session := *s
+		session.Guesses = slices.Clone(s.Guesses)
+		sessions[username] = &session
+	}
+	cp.Sessions = sessions
+	cp.Leaderboard = RankBoard{Positions: make(map[string]int, len(g.Leaderboard.Positions)), cmp: g.Leaderboard.cmp}
+	for _, s := range g.Leaderboard.Ranks {
+		cp.Leaderboard.Ranks = append(cp.Leaderboard.Ranks, sessions[s.Player.Username])
+		cp.Leaderboard.Positions[s.Player.Username] = g.Leaderboard.Positions[s.Player.Username]
+	}
+	return &cp
 }
 
 func (g *Game) Players() []string {
@@ -180,8 +366,12 @@ type Session struct {
 	bestGuess *word.Word
 	// the number of words this player has guessed for finished games. It is zero when guesses is empty
 	wordsCount int
-	Player     Player
-	Guesses    []word.Word
+	// maxGuesses is the number of guesses this player is allowed to make, DefaultGuesses is used when it is zero.
+	maxGuesses int
+	// expired is true when the game ended before this player finished
+	expired bool
+	Player  Player
+	Guesses []word.Word
 }
 
 // SetWordsCount ...
@@ -244,27 +434,98 @@ func (s *Session) Won() bool {
 	return s.BestGuess().Correct()
 }
 
-// CanPlay returns true if the user can still play (has not exceeded the maximum number of guesses)
+// limit returns the number of guesses this player is allowed to make or Unlimited.
+func (s *Session) limit() int {
+	if s.maxGuesses == 0 {
+		return DefaultGuesses
+	}
+	return s.maxGuesses
+}
+
+// CanPlay returns true if the user can still play (has not exceeded the maximum number of guesses or the time limit)
 func (s *Session) CanPlay() bool {
-	return len(s.Guesses) < MaxGuesses
+	if s.expired {
+		return false
+	}
+	return s.limit() == Unlimited || len(s.Guesses) < s.limit()
 }
 
 // Ended returns true if the user has finished up all their guesses or they have won the game (guessed the correct word)
 func (s *Session) Ended() bool {
-	return len(s.Guesses) == MaxGuesses || s.Won()
+	return !s.CanPlay() || s.Won()
+}
+
+// Failed returns true if the user can not play anymore without having guessed the correct word
+func (s *Session) Failed() bool {
+	return s.Ended() && !s.Won()
+}
+
+// SessionComparator determines the order of two sessions on the leaderboard.
+// It returns a negative number when s1 ranks higher than s2, a positive number when s2 ranks higher than s1
+// and zero when it can not tell them apart.
+type SessionComparator func(s1, s2 *Session) int
+
+// Chain returns a comparator that tries each of the comparators in order until one of them tells the sessions apart.
+func Chain(cmps ...SessionComparator) SessionComparator {
+	return func(s1, s2 *Session) int {
+		for _, cmp := range cmps {
+			if c := cmp(s1, s2); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
 }
 
-// TODO: it's possible to do later, let's continue; we can just add this to the game maybe when the user is choosing game settings for game mode
-// // SessionComparator determines the order of two sessions
-// // when the same number of words have been guessed.
-// type SessionComparator func(s1, s2 *Session) bool
+var (
+	// ByBestGuess ranks the session with more letters found in its best guess higher.
+	ByBestGuess SessionComparator = func(s1, s2 *Session) int {
+		return s2.BestGuess().Compare(s1.BestGuess())
+	}
+
+	// ByPlayTime ranks the session whose best guess was played first higher.
+	ByPlayTime SessionComparator = func(s1, s2 *Session) int {
+		t1, t2 := s1.BestGuess().PlayedAt, s2.BestGuess().PlayedAt
+		if !t1.Valid || !t2.Valid {
+			return 0
+		}
+		return t1.Time.Compare(t2.Time)
+	}
+
+	// ByWon ranks sessions that found the correct word higher than sessions that did not.
+	ByWon SessionComparator = func(s1, s2 *Session) int {
+		won1, won2 := s1.Won(), s2.Won()
+		switch {
+		case won1 == won2:
+			return 0
+		case won1:
+			return -1
+		default:
+			return 1
+		}
+	}
 
-// var (
-// 	ByPlayTime SessionComparator = func(s1, s2 *Session) bool {
-// 		return s1.BestGuess().PlayedAt.Time.Before(s2.BestGuess().PlayedAt.Time)
-// 	}
+	// ByGuessCount ranks the session that found the correct word in fewer guesses higher.
+	// Sessions are only compared when both of them found the correct word.
+	ByGuessCount SessionComparator = func(s1, s2 *Session) int {
+		if !s1.Won() || !s2.Won() {
+			return 0
+		}
+		return s1.WordsCount() - s2.WordsCount()
+	}
+)
 
-// 	ByGuessCount SessionComparator = func(s1, s2 *Session) bool {
-// 		return len(s1.Guesses) < len(s2.Guesses)
-// 	}
-// )
+var (
+	// ByClassic ranks sessions by their best guess, the first to play it wins a tie.
+	ByClassic = Chain(ByBestGuess, ByPlayTime)
+
+	// BySprint ranks sessions that found the correct word by the time it took them, ahead of sessions that did not.
+	// Since every session shares the same Game.StartedAt, comparing the time of the correct guesses is the same as
+	// comparing the time elapsed since the start of the game.
+	// Sessions that have not found the word yet are ranked like in ByClassic.
+	BySprint = Chain(ByWon, ByClassic)
+
+	// ByWizard ranks sessions that found the correct word by the number of guesses it took them, the first to finish wins a tie.
+	// Sessions that have not found the word yet are ranked like in ByClassic.
+	ByWizard = Chain(ByWon, ByGuessCount, ByClassic)
+)
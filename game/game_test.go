@@ -8,6 +8,7 @@ import (
 
 	"github.com/lordvidex/x/ptr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/kodekulture/wordle-server/game/word"
 )
@@ -323,3 +324,287 @@ func TestSession_Resync(t *testing.T) {
 		})
 	}
 }
+
+func TestGame_Sprint(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.Mode = Sprint
+	for _, p := range []string{"fela", "james", "jane"} {
+		g.Join(Player{Username: p})
+	}
+	g.Start()
+
+	play := func(player, guess string) {
+		w := word.New(guess)
+		_, _, err := g.Play(player, &w)
+		require.NoError(t, err)
+	}
+
+	t.Log("players are not limited to MaxGuesses in sprint mode")
+	for range MaxGuesses + 1 {
+		play("fela", "GAMAS")
+	}
+	assert.True(t, g.Sessions["fela"].CanPlay())
+	assert.False(t, g.Sessions["fela"].Ended())
+
+	t.Log("players who find the word rank by time, ahead of players who did not")
+	play("jane", "GAMES")
+	play("james", "JAMES")
+	play("james", "GAMES")
+	play("fela", "GAMES")
+
+	assert.Equal(t, 0, g.Leaderboard.Positions["jane"])
+	assert.Equal(t, 1, g.Leaderboard.Positions["james"])
+	assert.Equal(t, 2, g.Leaderboard.Positions["fela"])
+	assert.True(t, g.HasEnded())
+}
+
+func TestGame_Resync(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.Mode = Sprint
+	g.Join(Player{Username: "fela"})
+	g.Join(Player{Username: "jane"})
+	g.Start()
+	for range MaxGuesses {
+		w := word.New("GAMAS")
+		g.Play("fela", &w)
+	}
+	w := word.New("GAMES")
+	g.Play("jane", &w)
+
+	// restored games only keep their settings and the guesses of each player
+	restored := &Game{Settings: g.Settings, StartedAt: g.StartedAt, Sessions: make(map[string]*Session)}
+	for name, s := range g.Sessions {
+		restored.Sessions[name] = &Session{Player: s.Player, Guesses: s.Guesses}
+	}
+	restored.Leaderboard = NewRankBoard(restored.Sessions)
+	restored.Resync()
+
+	assert.True(t, restored.Sessions["fela"].CanPlay())
+	assert.Equal(t, 0, restored.Leaderboard.Positions["jane"])
+	assert.Equal(t, 1, restored.Leaderboard.Positions["fela"])
+	assert.Equal(t, g.LeaderboardVersion(), restored.LeaderboardVersion(), "every guess is a version of the leaderboard")
+}
+
+func TestGame_Score(t *testing.T) {
+	for _, mode := range []Mode{Classic, Sprint, Wizard} {
+		t.Run(string(mode), func(t *testing.T) {
+			g := New("fela", word.New("GAMES"))
+			g.Settings.Mode = mode
+			for _, p := range []string{"fela", "james", "jane", "ada"} {
+				g.Join(Player{Username: p})
+			}
+			g.Start()
+			for player, guesses := range map[string][]string{
+				"fela":  {"JAMES", "HELLO", "GAMES"},
+				"james": {"GAMAS"},
+				"jane":  {"JAMES", "GAMES"},
+			} {
+				for _, guess := range guesses {
+					w := word.New(guess)
+					_, _, err := g.Play(player, &w)
+					require.NoError(t, err)
+				}
+			}
+
+			ranks := g.Leaderboard.Ranks
+			for i := 1; i < len(ranks); i++ {
+				prev, curr := ranks[i-1].Player.Username, ranks[i].Player.Username
+				assert.LessOrEqual(t, g.Score(prev), g.Score(curr), "%s ranks higher than %s", prev, curr)
+			}
+		})
+	}
+}
+
+func TestGame_Restore(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	for _, p := range []string{"fela", "james", "jane"} {
+		g.Join(Player{Username: p})
+	}
+	g.Start()
+	for player, guess := range map[string]string{"fela": "JAMES", "jane": "GAMES"} {
+		w := word.New(guess)
+		g.Play(player, &w)
+	}
+	restore := func(ranks ...string) *Game {
+		restored := &Game{Settings: g.Settings, StartedAt: g.StartedAt, Sessions: make(map[string]*Session)}
+		for name, s := range g.Sessions {
+			restored.Sessions[name] = &Session{Player: s.Player, Guesses: s.Guesses}
+		}
+		restored.Restore(ranks)
+		return restored
+	}
+
+	restored := restore("jane", "fela")
+	assert.Equal(t, map[string]int{"jane": 0, "fela": 1, "james": 2}, restored.Leaderboard.Positions, "players without guesses rank last")
+	assert.Equal(t, g.LeaderboardVersion(), restored.LeaderboardVersion())
+
+	restored = restore("james", "fela", "jane", "unknown")
+	assert.Equal(t, map[string]int{"jane": 0, "fela": 1, "james": 2}, restored.Leaderboard.Positions, "a stored order that does not match the sessions is sorted")
+	assert.Equal(t, "jane", restored.Leaderboard.Ranks[0].Player.Username)
+}
+
+func TestGame_Wizard(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.Mode = Wizard
+	for _, p := range []string{"fela", "james", "jane"} {
+		g.Join(Player{Username: p})
+	}
+	g.Start()
+
+	play := func(player string, guesses ...string) {
+		for _, guess := range guesses {
+			w := word.New(guess)
+			_, _, err := g.Play(player, &w)
+			require.NoError(t, err)
+		}
+	}
+
+	play("fela", "JAMES", "HELLO", "GAMES")
+	play("james", "GAMAS")
+	play("jane", "JAMES", "GAMES")
+	assert.Equal(t, 0, g.Leaderboard.Positions["jane"], "fewest guesses wins")
+	assert.Equal(t, 1, g.Leaderboard.Positions["fela"])
+	assert.Equal(t, 2, g.Leaderboard.Positions["james"], "players who did not find the word rank last")
+
+	play("james", "GAMES")
+	assert.Equal(t, 0, g.Leaderboard.Positions["jane"], "first to finish wins a tie")
+	assert.Equal(t, 1, g.Leaderboard.Positions["james"])
+	assert.Equal(t, 2, g.Leaderboard.Positions["fela"])
+	assert.True(t, g.HasEnded())
+}
+
+func TestSessionComparator(t *testing.T) {
+	now := time.Now()
+	played := func(w word.Word, at time.Time) word.Word {
+		w.PlayedAt = sql.NullTime{Time: at, Valid: true}
+		return w
+	}
+	session := func(guesses ...word.Word) *Session {
+		s := &Session{}
+		for _, g := range guesses {
+			s.play(g)
+		}
+		return s
+	}
+	var (
+		fastWin   = session(played(words[2], now), played(words[0], now.Add(time.Second)))
+		slowWin   = session(played(words[0], now.Add(time.Minute)))
+		bestGuess = session(played(words[1], now))
+		noGuess   = session()
+	)
+	tests := []struct {
+		name   string
+		cmp    SessionComparator
+		s1, s2 *Session
+		want   int
+	}{
+		{"classic ranks by best guess", ByClassic, bestGuess, fastWin, 1},
+		{"classic ranks by time on ties", ByClassic, fastWin, slowWin, -1},
+		{"classic ranks empty sessions last", ByClassic, noGuess, bestGuess, 1},
+		{"sprint ranks by time among winners", BySprint, slowWin, fastWin, 1},
+		{"sprint ranks winners first", BySprint, slowWin, bestGuess, -1},
+		{"wizard ranks by guess count among winners", ByWizard, fastWin, slowWin, 1},
+		{"wizard ranks winners first", ByWizard, fastWin, bestGuess, -1},
+		{"guess count ignores sessions that have not won", ByGuessCount, bestGuess, fastWin, 0},
+		{"chain of nothing can not tell sessions apart", Chain(), fastWin, slowWin, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cmp(tt.s1, tt.s2)
+			switch {
+			case tt.want < 0:
+				assert.Negative(t, got)
+			case tt.want > 0:
+				assert.Positive(t, got)
+			default:
+				assert.Zero(t, got)
+			}
+		})
+	}
+}
+
+func TestGame_Expire(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.TimeLimit = 60
+	for _, p := range []string{"fela", "james", "jane"} {
+		g.Join(Player{Username: p})
+	}
+	g.Start()
+	*g.StartedAt = g.StartedAt.Add(-time.Hour)
+
+	w := word.New("GAMES")
+	g.Play("jane", &w)
+	w = word.New("GAMAS")
+	g.Play("james", &w)
+
+	g.Expire()
+	require.True(t, g.HasEnded())
+	assert.Equal(t, g.Deadline(), *g.EndedAt, "game ends at the deadline")
+	assert.False(t, g.Sessions["jane"].Failed())
+	for _, p := range []string{"fela", "james"} {
+		assert.True(t, g.Sessions[p].Ended())
+		assert.True(t, g.Sessions[p].Failed())
+	}
+
+	w = word.New("GAMES")
+	_, _, err := g.Play("fela", &w)
+	assert.ErrorIs(t, err, ErrSessionEnded)
+}
+
+func TestGame_HardMode(t *testing.T) {
+	g := New("fela", word.New("WORLD"))
+	g.Settings.HardMode = true
+	g.Join(Player{Username: "fela"})
+	g.Start()
+
+	w := word.New("WEIRD")
+	_, _, err := g.Play("fela", &w)
+	require.NoError(t, err)
+
+	w = word.New("WOULD")
+	_, _, err = g.Play("fela", &w)
+	var hintErr *word.HintError
+	require.ErrorAs(t, err, &hintErr)
+	assert.Equal(t, word.UseExisting, hintErr.Constraint)
+	assert.Len(t, g.Sessions["fela"].Guesses, 1, "rejected guesses are not played")
+
+	w = word.New("WORLD")
+	_, _, err = g.Play("fela", &w)
+	require.NoError(t, err)
+	assert.True(t, g.Sessions["fela"].Won())
+}
+
+func TestNewDaily(t *testing.T) {
+	today := time.Now()
+	played := func(guess string) word.Word {
+		w := word.New(guess)
+		w.Check(word.New("GAMES"))
+		return w
+	}
+	sessions := []*Session{
+		{Player: Player{Username: "fela"}, Guesses: []word.Word{played("JAMES"), played("GAMES")}},
+		{Player: Player{Username: "jane"}, Guesses: []word.Word{played("GAMES")}},
+		{Player: Player{Username: "james"}},
+	}
+	g := NewDaily(today, word.New("GAMES"), sessions)
+	assert.Equal(t, NewDaily(today, word.New("GAMES"), nil).ID, g.ID, "the daily game of a day always has the same id")
+	assert.Equal(t, Day(today), *g.StartedAt)
+	assert.False(t, g.HasEnded(), "the daily game ends with the day")
+	assert.Equal(t, 0, g.Leaderboard.Positions["jane"])
+	assert.Equal(t, 1, g.Leaderboard.Positions["fela"])
+
+	w := word.New("GAMES")
+	_, _, err := g.Play("james", &w)
+	require.NoError(t, err)
+	assert.False(t, g.HasEnded(), "the daily game does not end when every player has finished")
+	assert.Nil(t, ToResponse(*g, "james").CorrectWord)
+
+	w = word.New("GAMES")
+	_, _, err = g.Play("jane", &w)
+	assert.ErrorIs(t, err, ErrSessionEnded, "the daily game is played once")
+
+	yesterday := NewDaily(today.AddDate(0, 0, -1), word.New("WORLD"), nil)
+	assert.NotEqual(t, g.ID, yesterday.ID)
+	require.True(t, yesterday.HasEnded())
+	assert.Equal(t, "WORLD", ptr.ToString(ToResponse(*yesterday, "james").CorrectWord), "the word is revealed after the day")
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"cmp"
+	"slices"
+)
+
+// MaxHistory is the number of events broadcast in a room that are kept to be replayed to players.
+const MaxHistory = 256
+
+// history keeps the last events broadcast in a room ordered by their sequence number.
+type history struct {
+	events []Payload // events is used as a ring buffer
+	next   int       // next is the index of the next event
+	full   bool      // full is true once the oldest events are overwritten
+}
+
+func newHistory(size int) *history {
+	return &history{events: make([]Payload, size)}
+}
+
+// add adds an event to the history, the oldest event is dropped when the history is full.
+func (h *history) add(p Payload) {
+	h.events[h.next] = p
+	h.next = (h.next + 1) % len(h.events)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the events of the history, the oldest first.
+func (h *history) list() []Payload {
+	if !h.full {
+		return slices.Clone(h.events[:h.next])
+	}
+	return append(slices.Clone(h.events[h.next:]), h.events[:h.next]...)
+}
+
+// since returns the events with a sequence number greater than seq.
+// It returns false when some of these events have been dropped already.
+func (h *history) since(seq uint64) ([]Payload, bool) {
+	events := h.list()
+	if len(events) == 0 || events[len(events)-1].Seq <= seq {
+		return nil, true
+	}
+	if events[0].Seq > seq+1 {
+		return nil, false
+	}
+	i, _ := slices.BinarySearchFunc(events, seq+1, func(p Payload, seq uint64) int {
+		return cmp.Compare(p.Seq, seq)
+	})
+	return events[i:], true
+}
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_Since(t *testing.T) {
+	h := newHistory(3)
+	events, ok := h.since(0)
+	assert.True(t, ok)
+	assert.Empty(t, events)
+
+	for seq := uint64(1); seq <= 4; seq++ {
+		h.add(Payload{Type: CMessage, Seq: seq})
+	}
+	seqs := func(events []Payload) []uint64 {
+		res := make([]uint64, len(events))
+		for i, e := range events {
+			res[i] = e.Seq
+		}
+		return res
+	}
+	assert.Equal(t, []uint64{2, 3, 4}, seqs(h.list()), "the oldest event is dropped")
+
+	events, ok = h.since(2)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{3, 4}, seqs(events))
+
+	events, ok = h.since(1)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{2, 3, 4}, seqs(events))
+
+	events, ok = h.since(4)
+	assert.True(t, ok)
+	assert.Empty(t, events, "the player has seen every event")
+
+	_, ok = h.since(0)
+	assert.False(t, ok, "the first event is not kept anymore")
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// lobby keeps track of the players who are ready before the game of a room starts.
+type lobby struct {
+	ready map[string]bool
+	// countdown fires when the game should be started, it is set once the first player is ready
+	// if the game has a countdown.
+	countdown *time.Timer
+	deadline  time.Time
+}
+
+func newLobby() *lobby {
+	return &lobby{ready: make(map[string]bool)}
+}
+
+// join adds a player to the lobby, a player who is already in the lobby keeps his ready flag.
+func (l *lobby) join(username string) {
+	if _, ok := l.ready[username]; !ok {
+		l.ready[username] = false
+	}
+}
+
+// leave removes a player from the lobby.
+func (l *lobby) leave(username string) {
+	delete(l.ready, username)
+}
+
+// setReady sets the ready flag of a player in the lobby.
+func (l *lobby) setReady(username string, ready bool) {
+	l.ready[username] = ready
+}
+
+// allReady returns true if there are players in the lobby and all of them are ready.
+func (l *lobby) allReady() bool {
+	if len(l.ready) == 0 {
+		return false
+	}
+	for _, ready := range l.ready {
+		if !ready {
+			return false
+		}
+	}
+	return true
+}
+
+// startCountdown starts the countdown of the lobby if it has not started yet.
+func (l *lobby) startCountdown(d time.Duration) {
+	if l.countdown != nil {
+		return
+	}
+	l.deadline = time.Now().Add(d)
+	l.countdown = time.NewTimer(d)
+}
+
+// restartCountdown starts the countdown of the lobby again, even if it has started already.
+func (l *lobby) restartCountdown(d time.Duration) {
+	l.stop()
+	l.countdown = nil
+	l.startCountdown(d)
+}
+
+// expired returns the channel of the countdown.
+// It returns a nil channel, which blocks forever, when the countdown has not started or the lobby is nil.
+func (l *lobby) expired() <-chan time.Time {
+	if l == nil || l.countdown == nil {
+		return nil
+	}
+	return l.countdown.C
+}
+
+// stop stops the countdown of the lobby.
+func (l *lobby) stop() {
+	if l == nil || l.countdown == nil {
+		return
+	}
+	l.countdown.Stop()
+}
+
+// response returns the state of the lobby sent to clients.
+func (l *lobby) response() LobbyResponse {
+	players := make([]LobbyPlayerResponse, 0, len(l.ready))
+	for username, ready := range l.ready {
+		players = append(players, LobbyPlayerResponse{Username: username, Ready: ready})
+	}
+	slices.SortFunc(players, func(a, b LobbyPlayerResponse) int {
+		return strings.Compare(a.Username, b.Username)
+	})
+	res := LobbyResponse{Players: players}
+	if l.countdown != nil {
+		remaining := int(max(time.Until(l.deadline).Round(time.Second), 0).Seconds())
+		res.Countdown = &remaining
+	}
+	return res
+}
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+// MaxRounds is the maximum number of rounds a match can have
+const MaxRounds = 10
+
+// Round is a game of a match that has ended.
+type Round struct {
+	// Number is the position of the round in the match starting from 1
+	Number      int
+	GameID      uuid.UUID
+	CorrectWord string
+	EndedAt     time.Time
+}
+
+// Score is the number of points a player has collected in a match.
+type Score struct {
+	Username string
+	Points   int
+}
+
+// Match is a series of games played by the members of a room, each game is a round with a fresh word.
+// Players collect points in every round from their rank in the game.
+type Match struct {
+	ID        uuid.UUID
+	Creator   string
+	Rounds    int
+	CreatedAt time.Time
+	EndedAt   *time.Time
+	// Played contains the rounds that have ended in the order they were played
+	Played []Round
+	// Scores contains the points of every member of the match
+	Scores map[string]int
+}
+
+// NewMatch creates a match whose first round is `g`, the match has the id of its first game.
+func NewMatch(g *Game) *Match {
+	return &Match{
+		ID:        g.ID,
+		Creator:   g.Creator,
+		Rounds:    g.Rules().Rounds,
+		CreatedAt: time.Now(),
+		Scores:    make(map[string]int),
+	}
+}
+
+// Points returns the points earned in a round played by `players` players by the session at `rank`.
+// Only the players that have found the word earn points, the first one earns as many points as there are players.
+func Points(s *Session, rank, players int) int {
+	if !s.Won() {
+		return 0
+	}
+	return players - rank
+}
+
+// Score adds the points earned in the ended game `g` to the scores of the players and
+// returns the round that was recorded.
+func (m *Match) Score(g *Game) Round {
+	for username, s := range g.Sessions {
+		m.Scores[username] += Points(s, g.Leaderboard.Positions[username], len(g.Sessions))
+	}
+	round := Round{
+		Number:      len(m.Played) + 1,
+		GameID:      g.ID,
+		CorrectWord: g.CorrectWord.Word,
+		EndedAt:     time.Now(),
+	}
+	if g.EndedAt != nil {
+		round.EndedAt = *g.EndedAt
+	}
+	m.Played = append(m.Played, round)
+	if m.HasEnded() {
+		m.EndedAt = &round.EndedAt
+	}
+	return round
+}
+
+// NextRound returns the game of the next round played with `correctWord` by the players of `prev`.
+// The creator of `prev` stays the creator of the next round, the ownership of the room may have been transferred.
+func (m *Match) NextRound(prev *Game, correctWord word.Word) *Game {
+	g := New(prev.Creator, correctWord)
+	g.Settings = prev.Settings
+	for _, s := range prev.Sessions {
+		g.Join(s.Player)
+	}
+	return g
+}
+
+// Round returns the number of the round currently played, starting from 1.
+func (m *Match) Round() int {
+	return min(len(m.Played)+1, m.Rounds)
+}
+
+// HasStarted returns true if at least one round of the match has ended.
+func (m *Match) HasStarted() bool {
+	return len(m.Played) > 0
+}
+
+// HasEnded returns true if all the rounds of the match have been played.
+func (m *Match) HasEnded() bool {
+	return len(m.Played) >= m.Rounds
+}
+
+// IsMember returns true if the player has taken part in the match.
+func (m *Match) IsMember(username string) bool {
+	_, ok := m.Scores[username]
+	return ok
+}
+
+// Leaderboard returns the scores of the players sorted by points, players with the same points are sorted by name.
+func (m *Match) Leaderboard() []Score {
+	scores := make([]Score, 0, len(m.Scores))
+	for username, points := range m.Scores {
+		scores = append(scores, Score{Username: username, Points: points})
+	}
+	slices.SortFunc(scores, func(a, b Score) int {
+		if a.Points != b.Points {
+			return b.Points - a.Points
+		}
+		return cmp.Compare(a.Username, b.Username)
+	})
+	return scores
+}
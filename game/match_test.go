@@ -0,0 +1,56 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+func TestMatch_Score(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.Rounds = 2
+	for _, p := range []string{"fela", "james", "jane"} {
+		g.Join(Player{Username: p})
+	}
+	g.Start()
+	m := NewMatch(g)
+	require.Equal(t, 2, m.Rounds)
+
+	play := func(g *Game, player string, guesses ...string) {
+		for _, guess := range guesses {
+			w := word.New(guess)
+			_, _, err := g.Play(player, &w)
+			require.NoError(t, err)
+		}
+	}
+
+	play(g, "jane", "GAMES")
+	play(g, "fela", "JAMES", "GAMES")
+	g.Expire()
+	round := m.Score(g)
+	assert.Equal(t, Round{Number: 1, GameID: g.ID, CorrectWord: "GAMES", EndedAt: *g.EndedAt}, round)
+	assert.Equal(t, map[string]int{"jane": 3, "fela": 2, "james": 0}, m.Scores, "players who did not find the word earn no points")
+	assert.True(t, m.HasStarted())
+	assert.False(t, m.HasEnded())
+	assert.Equal(t, 2, m.Round())
+
+	next := m.NextRound(g, word.New("WORLD"))
+	assert.NotEqual(t, g.ID, next.ID)
+	assert.Equal(t, g.Settings, next.Settings)
+	assert.ElementsMatch(t, g.Players(), next.Players(), "the same players play every round")
+	assert.Nil(t, next.StartedAt)
+
+	next.Start()
+	play(next, "james", "WORLD")
+	play(next, "fela", "WORLD")
+	next.Expire()
+	m.Score(next)
+	assert.True(t, m.HasEnded())
+	assert.NotNil(t, m.EndedAt)
+	assert.Equal(t, []Score{{"fela", 4}, {"james", 3}, {"jane", 3}}, m.Leaderboard())
+	assert.True(t, m.IsMember("james"))
+	assert.False(t, m.IsMember("john"))
+}
@@ -0,0 +1,28 @@
+package game
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action is a moderation action taken by the creator of a game in its room
+type Action string
+
+const (
+	// ActionKick removes a player from the room, the player can join again
+	ActionKick Action = "kick"
+	// ActionBan removes a player from the room, the player can not join again
+	ActionBan Action = "ban"
+	// ActionTransfer makes another player the creator of the game
+	ActionTransfer Action = "transfer"
+)
+
+// Moderation records a moderation action taken in the room of a game
+type Moderation struct {
+	GameID    uuid.UUID
+	Action    Action
+	Moderator string // Moderator is the username of the creator who took the action
+	Target    string // Target is the username of the player the action was taken against
+	CreatedAt time.Time
+}
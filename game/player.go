@@ -1,5 +1,15 @@
 package game
 
+// Role determines what a player connected to a room is allowed to do
+type Role string
+
+const (
+	// RolePlayer plays the game of the room
+	RolePlayer Role = "player"
+	// RoleSpectator watches the game of the room and chats without playing
+	RoleSpectator Role = "spectator"
+)
+
 type Player struct {
 	Password  string
 	Username  string
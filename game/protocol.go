@@ -0,0 +1,216 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+//go:generate go run ../cmd/schema -o ../docs/protocol.schema.json
+
+const (
+	// ProtocolV1 sends errors as plain messages, it is used by clients that do not ask for a version.
+	ProtocolV1 = 1
+	// ProtocolV2 sends errors as an ErrorResponse with a stable code.
+	ProtocolV2 = 2
+	// ProtocolV3 sends the entries of the leaderboard changed by a guess instead of the whole leaderboard.
+	ProtocolV3 = 3
+	// ProtocolVersion is the latest version of the protocol of the live socket.
+	ProtocolVersion = ProtocolV3
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported protocol version")
+
+// NegotiateVersion returns the version of the protocol used with a client that asks for the version `requested`,
+// it is the latest version supported by both the client and the server.
+// Clients that do not ask for a version use ProtocolV1.
+func NegotiateVersion(requested string) (int, error) {
+	if requested == "" {
+		return ProtocolV1, nil
+	}
+	v, err := strconv.Atoi(requested)
+	if err != nil || v < ProtocolV1 {
+		return 0, ErrUnsupportedVersion
+	}
+	return min(v, ProtocolVersion), nil
+}
+
+// ErrorCode identifies the reason of an error sent in a `CError` event, codes never change between versions.
+type ErrorCode string
+
+const (
+	ErrCodeUnsupportedAction ErrorCode = "unsupported_action"
+	ErrCodeUnknownEvent      ErrorCode = "unknown_event"
+	ErrCodeInvalidMessage    ErrorCode = "invalid_message"
+	ErrCodeNotCreator        ErrorCode = "not_creator"
+	ErrCodeSpectator         ErrorCode = "spectator"
+	ErrCodeAlreadyStarted    ErrorCode = "already_started"
+	ErrCodeNotReady          ErrorCode = "not_ready"
+	ErrCodeNotActive         ErrorCode = "not_active"
+	ErrCodeNoSession         ErrorCode = "no_session"
+	ErrCodeAlreadyWon        ErrorCode = "already_won"
+	ErrCodeNoAttempts        ErrorCode = "no_attempts"
+	ErrCodeInvalidLength     ErrorCode = "invalid_length"
+	ErrCodeInvalidCharacters ErrorCode = "invalid_characters"
+	ErrCodeInvalidWord       ErrorCode = "invalid_word"
+	ErrCodeHintNotUsed       ErrorCode = "hint_not_used"
+	ErrCodeInvalidTarget     ErrorCode = "invalid_target"
+	ErrCodeUnknownPlayer     ErrorCode = "unknown_player"
+	ErrCodeRateLimited       ErrorCode = "rate_limited"
+	ErrCodeInternal          ErrorCode = "internal"
+)
+
+// ErrorCodes contains every code an ErrorResponse can have.
+var ErrorCodes = []ErrorCode{
+	ErrCodeUnsupportedAction, ErrCodeUnknownEvent, ErrCodeInvalidMessage, ErrCodeNotCreator, ErrCodeSpectator,
+	ErrCodeAlreadyStarted, ErrCodeNotReady, ErrCodeNotActive, ErrCodeNoSession, ErrCodeAlreadyWon, ErrCodeNoAttempts,
+	ErrCodeInvalidLength, ErrCodeInvalidCharacters, ErrCodeInvalidWord, ErrCodeHintNotUsed, ErrCodeInvalidTarget,
+	ErrCodeUnknownPlayer, ErrCodeRateLimited, ErrCodeInternal,
+}
+
+// ErrorResponse is the data of a `CError` event.
+type ErrorResponse struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	// Hint is set when a guess does not use the hints revealed in hard mode
+	Hint *HintErrorResponse `json:"hint,omitempty"`
+	// RetryAfter is the number of milliseconds to wait before sending the event again, it is set with ErrCodeRateLimited
+	RetryAfter *int `json:"retry_after,omitempty"`
+}
+
+// versioned is the data of an event whose format depends on the version of the protocol.
+type versioned interface {
+	forVersion(version int) any
+}
+
+// forVersion returns the data of the error sent to clients using the `version` of the protocol.
+func (e ErrorResponse) forVersion(version int) any {
+	if version >= ProtocolV2 {
+		return e
+	}
+	if e.Hint != nil {
+		return *e.Hint
+	}
+	return e.Message
+}
+
+type (
+	// MessageRequest is the text of a chat message
+	MessageRequest string
+	// PlayRequest is the word guessed by a player
+	PlayRequest string
+	// StartRequest has no data
+	StartRequest struct{}
+	// ReadyRequest is false when the player is not ready anymore, it is true when it is not set
+	ReadyRequest bool
+	// SyncRequest is the sequence number of the last event received by the player
+	SyncRequest uint64
+	// ModerationRequest is the username of the player targeted by the creator
+	ModerationRequest string
+	// LeaderboardRequest has no data
+	LeaderboardRequest struct{}
+)
+
+// requests contains the default data of every event sent by players, the data sent is decoded into its type.
+var requests = map[Event]any{
+	SMessage:  MessageRequest(""),
+	SPlay:     PlayRequest(""),
+	SStart:    StartRequest{},
+	SReady:    ReadyRequest(true),
+	SSync:     SyncRequest(0),
+	SKick:     ModerationRequest(""),
+	SBan:      ModerationRequest(""),
+	STransfer: ModerationRequest(""),
+
+	SLeaderboard: LeaderboardRequest{},
+}
+
+// responses contains the type of the data of every event sent to players.
+var responses = map[Event]reflect.Type{
+	CMessage:      reflect.TypeFor[string](),
+	CPlay:         reflect.TypeFor[PlayerGuessResponse](),
+	CFinish:       reflect.TypeFor[string](),
+	CRound:        reflect.TypeFor[MatchResponse](),
+	CTick:         reflect.TypeFor[TickResponse](),
+	CStart:        reflect.TypeFor[string](),
+	CLobby:        reflect.TypeFor[LobbyResponse](),
+	CJoin:         reflect.TypeFor[string](),
+	CLeave:        reflect.TypeFor[string](),
+	CReconnecting: reflect.TypeFor[string](),
+	CSpectators:   reflect.TypeFor[SpectatorsResponse](),
+	CModeration:   reflect.TypeFor[ModerationResponse](),
+	COwner:        reflect.TypeFor[OwnerResponse](),
+	CData:         reflect.TypeFor[InitialData](),
+	CError:        reflect.TypeFor[ErrorResponse](),
+	CLeaderboard:  reflect.TypeFor[LeaderboardSnapshotResponse](),
+}
+
+// decodeRequest decodes the data of an event sent by a player with the codec `c` into the type of the data of the event.
+// The data of unknown events is nil, the room rejects them.
+func decodeRequest(c Codec, event Event, data []byte) (any, error) {
+	def, ok := requests[event]
+	if !ok {
+		return nil, nil
+	}
+	if len(data) == 0 {
+		return def, nil
+	}
+	// the data is decoded into a pointer which stays nil when the data is null
+	v := reflect.New(reflect.PointerTo(reflect.TypeOf(def)))
+	if err := c.Unmarshal(data, v.Interface()); err != nil {
+		return nil, err
+	}
+	if v.Elem().IsNil() {
+		return def, nil
+	}
+	return v.Elem().Elem().Interface(), nil
+}
+
+// storedGuess is the data of a stored `CPlay` event, it keeps the whole leaderboard sent to clients using older versions of the protocol.
+type storedGuess struct {
+	PlayerGuessResponse
+	Snapshot LeaderboardResponse `json:"snapshot,omitempty"`
+}
+
+// MarshalEvent encodes an event broadcast in a room so that it can be stored, it is decoded with UnmarshalEvent.
+func MarshalEvent(p Payload) ([]byte, error) {
+	if g, ok := p.Data.(PlayerGuessResponse); ok {
+		p.Data = storedGuess{PlayerGuessResponse: g, Snapshot: g.snapshot}
+	}
+	return json.Marshal(p)
+}
+
+// UnmarshalEvent decodes an event encoded with MarshalEvent, its data is decoded into the type of the data of the event,
+// so that a restored event is sent to every client in the format of its version of the protocol.
+func UnmarshalEvent(b []byte) (Payload, error) {
+	var stored struct {
+		Payload
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return Payload{}, err
+	}
+	p := stored.Payload
+	typ, ok := responses[p.Type]
+	switch {
+	case p.Type == CPlay:
+		var g storedGuess
+		if err := json.Unmarshal(stored.Data, &g); err != nil {
+			return Payload{}, err
+		}
+		g.snapshot = g.Snapshot
+		p.Data = g.PlayerGuessResponse
+	case ok:
+		v := reflect.New(typ)
+		if err := json.Unmarshal(stored.Data, v.Interface()); err != nil {
+			return Payload{}, err
+		}
+		p.Data = v.Elem().Interface()
+	default:
+		if err := json.Unmarshal(stored.Data, &p.Data); err != nil {
+			return Payload{}, err
+		}
+	}
+	return p, nil
+}
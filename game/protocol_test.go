@@ -0,0 +1,120 @@
+package game
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      int
+		wantErr   bool
+	}{
+		{requested: "", want: ProtocolV1},
+		{requested: "1", want: ProtocolV1},
+		{requested: "2", want: ProtocolV2},
+		{requested: "3", want: ProtocolV3},
+		{requested: "99", want: ProtocolVersion},
+		{requested: "0", wantErr: true},
+		{requested: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NegotiateVersion(tt.requested)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrUnsupportedVersion, tt.requested)
+			continue
+		}
+		assert.NoError(t, err, tt.requested)
+		assert.Equal(t, tt.want, got, tt.requested)
+	}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		event   Event
+		data    string
+		want    any
+		wantErr bool
+	}{
+		{event: SPlay, data: `"GAMES"`, want: PlayRequest("GAMES")},
+		{event: SPlay, data: `42`, wantErr: true},
+		{event: SMessage, data: `"hello"`, want: MessageRequest("hello")},
+		{event: SReady, data: ``, want: ReadyRequest(true)},
+		{event: SReady, data: `false`, want: ReadyRequest(false)},
+		{event: SSync, data: `42`, want: SyncRequest(42)},
+		{event: SSync, data: `-1`, wantErr: true},
+		{event: SKick, data: `"james"`, want: ModerationRequest("james")},
+		{event: SStart, data: `null`, want: StartRequest{}},
+		{event: "server/unknown", data: `"data"`, want: nil},
+	}
+	for _, tt := range tests {
+		got, err := decodeRequest(JSON, tt.event, []byte(tt.data))
+		if tt.wantErr {
+			assert.Error(t, err, tt.event)
+			continue
+		}
+		assert.NoError(t, err, tt.event)
+		assert.Equal(t, tt.want, got, tt.event)
+	}
+}
+
+func TestErrorResponse_forVersion(t *testing.T) {
+	e := ErrorResponse{Code: ErrCodeInvalidWord, Message: "Invalid english word"}
+	assert.Equal(t, "Invalid english word", e.forVersion(ProtocolV1))
+	assert.Equal(t, e, e.forVersion(ProtocolV2))
+
+	hint := HintErrorResponse{Message: "hint", Constraint: "use_existing", Letter: "A"}
+	e = ErrorResponse{Code: ErrCodeHintNotUsed, Message: "hint", Hint: &hint}
+	assert.Equal(t, hint, e.forVersion(ProtocolV1), "the first version sends the hint as is")
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+	snapshot := LeaderboardResponse{{Rank: 1, Username: "fela"}, {Rank: 2, Username: "james"}}
+	guess := PlayerGuessResponse{Leaderboard: snapshot[1:], Version: 3, snapshot: snapshot}
+	events := []Payload{
+		{Type: CPlay, Data: guess, From: "james", Seq: 1},
+		{Type: CError, Data: ErrorResponse{Code: ErrCodeInternal, Message: "Failed to start game"}, Seq: 2},
+		{Type: CMessage, Data: "hello", From: "fela", Seq: 3},
+		{Type: CSpectators, Data: SpectatorsResponse{Count: 2}, Seq: 4},
+	}
+	for _, e := range events {
+		b, err := MarshalEvent(e)
+		require.NoError(t, err)
+		got, err := UnmarshalEvent(b)
+		require.NoError(t, err)
+		assert.Equal(t, e, got, e.Type)
+	}
+
+	b, err := MarshalEvent(events[0])
+	require.NoError(t, err)
+	got, err := UnmarshalEvent(b)
+	require.NoError(t, err)
+	v1 := got.Data.(versioned).forVersion(ProtocolV1).(PlayerGuessResponse)
+	assert.Equal(t, snapshot, v1.Leaderboard, "older clients receive the whole leaderboard of a restored guess")
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	defs := schema["$defs"].(map[string]any)
+	for _, name := range []string{"ServerPlay", "ClientPlay", "ClientError", "ErrorResponse", "InitialData", "GameSettings"} {
+		assert.Contains(t, defs, name)
+	}
+	code := defs["ErrorCode"].(map[string]any)
+	assert.Contains(t, code["enum"], ErrCodeAlreadyWon)
+
+	// InitialData embeds Response
+	data := defs["InitialData"].(map[string]any)["properties"].(map[string]any)
+	assert.Contains(t, data, "creator")
+	assert.Contains(t, data, "active")
+
+	b, err := json.MarshalIndent(schema, "", "  ")
+	require.NoError(t, err)
+	generated, err := os.ReadFile("../docs/protocol.schema.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(b), string(generated), "the schema must be regenerated with `go generate ./game`")
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodekulture/wordle-server/internal/config"
+)
+
+// Limit is the rate at which a player can send an event, a player can send Burst events at once.
+// The event is not limited when Rate is not positive.
+type Limit struct {
+	// Rate is the number of events per second
+	Rate  float64
+	Burst int
+}
+
+// DefaultLimits contains the limits of the events sent by players, the events without a limit share defaultLimit.
+var DefaultLimits = map[Event]Limit{
+	SMessage:     {Rate: 1, Burst: 5},
+	SPlay:        {Rate: 2, Burst: 5},
+	SStart:       {Rate: 1, Burst: 3},
+	SReady:       {Rate: 2, Burst: 5},
+	SSync:        {Rate: 1, Burst: 3},
+	SLeaderboard: {Rate: 1, Burst: 3},
+	SKick:        {Rate: 1, Burst: 3},
+	SBan:         {Rate: 1, Burst: 3},
+	STransfer:    {Rate: 1, Burst: 3},
+}
+
+var defaultLimit = Limit{Rate: 1, Burst: 5}
+
+var (
+	// limits is read from RATE_LIMITS, e.g. `server/message=0.5:3,server/play=1:5`
+	limits = config.GetOrDefault("RATE_LIMITS", DefaultLimits, ParseLimits)
+	// maxMessageSize is the maximum size in bytes of a message sent by a player, larger messages close the connection
+	maxMessageSize = config.GetOrDefault("MAX_MESSAGE_SIZE", int64(4096), func(s string) (int64, error) {
+		return strconv.ParseInt(s, 10, 64)
+	})
+	// maxViolations is the number of messages over their limit a player can send in a minute before being disconnected
+	maxViolations = config.GetOrDefault("MAX_VIOLATIONS", 10, strconv.Atoi)
+)
+
+var (
+	ErrInvalidLimit = errors.New("invalid rate limit")
+	ErrRateLimited  = errors.New("too many messages")
+)
+
+// ParseLimits parses limits formatted as `event=rate:burst` separated by commas, the rate is a number of events per second.
+// The events that are not set keep their default limit.
+func ParseLimits(s string) (map[Event]Limit, error) {
+	res := maps.Clone(DefaultLimits)
+	for _, part := range strings.Split(s, ",") {
+		event, limit, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLimit, part)
+		}
+		rate, burst, ok := strings.Cut(limit, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLimit, part)
+		}
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLimit, part)
+		}
+		b, err := strconv.Atoi(burst)
+		if err != nil || b < 1 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLimit, part)
+		}
+		res[Event(event)] = Limit{Rate: r, Burst: b}
+	}
+	return res, nil
+}
+
+// bucket is a token bucket, it holds up to Burst tokens and is refilled at Rate tokens per second.
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	return &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+}
+
+// take takes a token from the bucket, it returns how long to wait before a token is available when the bucket is empty.
+func (b *bucket) take(now time.Time) time.Duration {
+	if b.limit.Rate <= 0 {
+		return 0
+	}
+	b.tokens = min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+// limiter limits the events sent by a player with a bucket for every event.
+// It is shared by the read goroutines of the connections of the player.
+type limiter struct {
+	mu      sync.Mutex
+	limits  map[Event]Limit
+	buckets map[Event]*bucket
+	// violations is emptied by the messages sent over their limit, the player is disconnected once it is empty
+	violations *bucket
+}
+
+func newLimiter(limits map[Event]Limit, maxViolations int, now time.Time) *limiter {
+	return &limiter{
+		limits:     limits,
+		buckets:    make(map[Event]*bucket),
+		violations: newBucket(Limit{Rate: float64(maxViolations) / 60, Burst: maxViolations}, now),
+	}
+}
+
+// allow takes a token for the event, it returns how long the player must wait before sending the event again
+// when the event is over its limit. The events without a limit share the same bucket.
+func (l *limiter) allow(event Event, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit, ok := l.limits[event]
+	if !ok {
+		event, limit = "", defaultLimit
+	}
+	b := l.buckets[event]
+	if b == nil {
+		b = newBucket(limit, now)
+		l.buckets[event] = b
+	}
+	return b.take(now)
+}
+
+// offend records a message sent over its limit, it returns true when the player keeps sending messages over their limit.
+func (l *limiter) offend(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.violations.take(now) > 0
+}
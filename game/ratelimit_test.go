@@ -0,0 +1,59 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimits(t *testing.T) {
+	got, err := ParseLimits("server/message=0.5:3, server/play=0:1")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Rate: 0.5, Burst: 3}, got[SMessage])
+	assert.Equal(t, Limit{Rate: 0, Burst: 1}, got[SPlay])
+	assert.Equal(t, DefaultLimits[SReady], got[SReady], "the events that are not set keep their default limit")
+	assert.Equal(t, Limit{Rate: 1, Burst: 5}, DefaultLimits[SMessage], "the default limits are not changed")
+
+	for _, s := range []string{"server/message", "server/message=1", "server/message=a:1", "server/message=1:0"} {
+		_, err := ParseLimits(s)
+		assert.ErrorIs(t, err, ErrInvalidLimit, s)
+	}
+}
+
+func TestBucket_take(t *testing.T) {
+	now := time.Now()
+	b := newBucket(Limit{Rate: 2, Burst: 2}, now)
+	assert.Zero(t, b.take(now))
+	assert.Zero(t, b.take(now))
+	assert.Equal(t, 500*time.Millisecond, b.take(now), "a token is added every 500ms")
+	assert.Zero(t, b.take(now.Add(500*time.Millisecond)))
+
+	now = now.Add(time.Hour)
+	assert.Zero(t, b.take(now))
+	assert.Zero(t, b.take(now))
+	assert.NotZero(t, b.take(now), "the bucket holds at most Burst tokens")
+
+	unlimited := newBucket(Limit{}, now)
+	for range 100 {
+		assert.Zero(t, unlimited.take(now))
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	l := newLimiter(map[Event]Limit{SPlay: {Rate: 1, Burst: 1}, SMessage: {Rate: 1, Burst: 1}}, 2, now)
+	assert.Zero(t, l.allow(SPlay, now))
+	assert.Equal(t, time.Second, l.allow(SPlay, now))
+	assert.Zero(t, l.allow(SMessage, now), "every event has its own bucket")
+	for range defaultLimit.Burst {
+		assert.Zero(t, l.allow("server/unknown", now))
+	}
+	assert.NotZero(t, l.allow("client/play", now), "the events without a limit share the same bucket")
+
+	assert.False(t, l.offend(now))
+	assert.False(t, l.offend(now))
+	assert.True(t, l.offend(now), "players are disconnected once they reach the maximum number of violations")
+	assert.False(t, l.offend(now.Add(time.Minute)), "violations are forgotten over time")
+}
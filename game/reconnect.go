@@ -0,0 +1,39 @@
+package game
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/kodekulture/wordle-server/internal/config"
+)
+
+// maxMissed is the maximum number of messages kept for a player who is reconnecting, older messages are dropped.
+const maxMissed = 100
+
+// reconnectGrace is how long a player whose connection dropped can resume his session before the other players
+// are told that he has left.
+var reconnectGrace = config.GetOrDefault("RECONNECT_GRACE", 30*time.Second, time.ParseDuration)
+
+// away is a player whose connection dropped and who can resume his session with his resume token.
+type away struct {
+	token string
+	// missed contains the messages broadcast in the room since the connection dropped
+	missed []Payload
+	timer  *time.Timer
+}
+
+// miss keeps a message broadcast while the player is away so that it can be replayed when he resumes his session.
+func (a *away) miss(p Payload) {
+	if len(a.missed) == maxMissed {
+		a.missed = a.missed[1:]
+	}
+	a.missed = append(a.missed, p)
+}
+
+// newResumeToken returns a random token used by a player to resume his session after his connection dropped.
+func newResumeToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
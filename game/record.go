@@ -0,0 +1,117 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+var ErrInvalidLog = errors.New("log does not start with the creation of a game")
+
+// RecordKind is the kind of change of a game written to its log.
+type RecordKind string
+
+const (
+	// RecordCreate is the creation of a game, it is the first record of a log
+	RecordCreate RecordKind = "create"
+	// RecordJoin is a player joining the game
+	RecordJoin RecordKind = "join"
+	// RecordLeave is a player removed from the game before it starts
+	RecordLeave RecordKind = "leave"
+	// RecordOwner is a player becoming the creator of the game
+	RecordOwner RecordKind = "owner"
+	// RecordStart is the start of the game
+	RecordStart RecordKind = "start"
+	// RecordGuess is a guess played by a player, it is written before the guess is played
+	RecordGuess RecordKind = "guess"
+	// RecordFinish is the end of the game
+	RecordFinish RecordKind = "finish"
+)
+
+// Record is an entry of the append-only log of a game, Replay rebuilds a game from its records.
+type Record struct {
+	Kind RecordKind `json:"kind"`
+	At   time.Time  `json:"at"`
+	// Game is the game created by a RecordCreate record, without its sessions
+	Game *Game `json:"game,omitempty"`
+	// Player is the player of a RecordJoin record, without the password
+	Player *Player `json:"player,omitempty"`
+	// Username is the player of the other records
+	Username string `json:"username,omitempty"`
+	// Word is the word guessed in a RecordGuess record
+	Word string `json:"word,omitempty"`
+}
+
+// CreateRecord returns the record of the creation of a game.
+func CreateRecord(g *Game) Record {
+	cp := *g
+	cp.Sessions = nil
+	cp.Leaderboard = RankBoard{}
+	return Record{Kind: RecordCreate, At: g.CreatedAt, Game: &cp}
+}
+
+func joinRecord(p Player) Record {
+	p.Password = ""
+	return Record{Kind: RecordJoin, At: time.Now().UTC(), Player: &p}
+}
+
+func leaveRecord(username string) Record {
+	return Record{Kind: RecordLeave, At: time.Now().UTC(), Username: username}
+}
+
+func ownerRecord(username string) Record {
+	return Record{Kind: RecordOwner, At: time.Now().UTC(), Username: username}
+}
+
+func startRecord(at time.Time) Record {
+	return Record{Kind: RecordStart, At: at}
+}
+
+func guessRecord(username, guess string, at time.Time) Record {
+	return Record{Kind: RecordGuess, At: at, Username: username, Word: guess}
+}
+
+func finishRecord(g *Game) Record {
+	return Record{Kind: RecordFinish, At: *g.EndedAt}
+}
+
+// Replay rebuilds a game from the records of its log, replaying the same records always returns the same game.
+// Guesses that were rejected when they were played are rejected again.
+func Replay(records []Record) (*Game, error) {
+	if len(records) == 0 || records[0].Kind != RecordCreate || records[0].Game == nil {
+		return nil, ErrInvalidLog
+	}
+	g := *records[0].Game
+	g.Sessions = make(map[string]*Session)
+	for _, rec := range records[1:] {
+		switch rec.Kind {
+		case RecordJoin:
+			if rec.Player != nil {
+				g.Join(*rec.Player)
+			}
+		case RecordLeave:
+			g.Leave(rec.Username)
+		case RecordOwner:
+			g.Creator = rec.Username
+		case RecordStart:
+			g.startAt(rec.At)
+		case RecordGuess:
+			if g.StartedAt == nil || g.HasEnded() {
+				continue
+			}
+			w := word.New(rec.Word)
+			_, _, _ = g.playAt(rec.Username, &w, rec.At)
+		case RecordFinish:
+			g.expireAt(rec.At)
+		default:
+			return nil, fmt.Errorf("unknown record %q", rec.Kind)
+		}
+	}
+	if g.StartedAt == nil {
+		g.Leaderboard = NewRankBoard(g.Sessions)
+		g.Leaderboard.cmp = g.Settings.comparator()
+	}
+	return &g, nil
+}
@@ -21,6 +21,7 @@ type Response struct {
 	CorrectWord     *string             `json:"correct_word,omitempty"`     // returned only if game has ended
 	Guesses         []GuessResponse     `json:"guesses,omitempty"`          // contains the guesses of the current player
 	GamePerformance LeaderboardResponse `json:"game_performance,omitempty"` // contains the best guesses of all players
+	Settings        GameSettings        `json:"settings"`
 	ID              uuid.UUID           `json:"id"`
 }
 
@@ -38,7 +39,29 @@ type PlayerGuessResponse struct {
 	// RankOffset is the amount of players that this user has displaced in the leaderboard
 	// this field is set when the game is active, and the user's guess made him move up the leaderboard
 	RankOffset *int `json:"rank_offset,omitempty"`
-	// Leaderboard contains best guesses for this user.
+	// Leaderboard contains the entries of the leaderboard changed by the guess, the entry of the user and the entries
+	// of the players he displaced. Clients using a version older than ProtocolV3 receive the whole leaderboard.
+	Leaderboard LeaderboardResponse `json:"leaderboard"`
+	// Version is the version of the leaderboard after the guess, clients that missed a version ask for the whole
+	// leaderboard with a `SLeaderboard` event.
+	Version int `json:"version"`
+
+	// snapshot is the whole leaderboard after the guess
+	snapshot LeaderboardResponse
+}
+
+// forVersion returns the data of the guess sent to clients using the `version` of the protocol.
+func (r PlayerGuessResponse) forVersion(version int) any {
+	if version >= ProtocolV3 || r.snapshot == nil {
+		return r
+	}
+	r.Leaderboard = r.snapshot
+	return r
+}
+
+// LeaderboardSnapshotResponse contains the whole leaderboard of a game and its version
+type LeaderboardSnapshotResponse struct {
+	Version     int                 `json:"version"`
 	Leaderboard LeaderboardResponse `json:"leaderboard"`
 }
 
@@ -52,11 +75,142 @@ type PlayerSummaryResponse struct {
 	WordsPlayed int           `json:"words_played"`
 }
 
+// HintErrorResponse tells a player which hint their guess did not use in hard mode
+type HintErrorResponse struct {
+	Message string `json:"message"`
+	// Constraint is the violated constraint, either word.KeepCorrect or word.UseExisting
+	Constraint string `json:"constraint"`
+	Letter     string `json:"letter"`
+	// Position is the 1-based position the letter must be kept at, it is set only for word.KeepCorrect
+	Position *int `json:"position,omitempty"`
+}
+
+func NewHintErrorResponse(err *word.HintError) HintErrorResponse {
+	res := HintErrorResponse{
+		Message:    err.Error(),
+		Constraint: err.Constraint,
+		Letter:     string(err.Letter),
+	}
+	if err.Constraint == word.KeepCorrect {
+		res.Position = ptr.Obj(err.Position + 1)
+	}
+	return res
+}
+
+// TickResponse reminds players about the time left in the game
+type TickResponse struct {
+	// Remaining is the number of seconds left before the game ends
+	Remaining int `json:"remaining"`
+}
+
+// MatchResponse contains the scores of a match and the rounds played so far
+type MatchResponse struct {
+	ID uuid.UUID `json:"id"`
+	// Round is the number of the round currently played, starting from 1
+	Round       int                  `json:"round"`
+	Rounds      int                  `json:"rounds"`
+	CreatedAt   time.Time            `json:"created_at"`
+	EndedAt     *time.Time           `json:"ended_at"`
+	Leaderboard []MatchScoreResponse `json:"leaderboard"`
+	History     []RoundResponse      `json:"history"`
+}
+
+type MatchScoreResponse struct {
+	Rank     int    `json:"rank"`
+	Username string `json:"username"`
+	Points   int    `json:"points"`
+}
+
+// RoundResponse describes a round that has ended, the game of the round is found with its GameID
+type RoundResponse struct {
+	Number      int       `json:"number"`
+	GameID      uuid.UUID `json:"game_id"`
+	CorrectWord string    `json:"correct_word"`
+	EndedAt     time.Time `json:"ended_at"`
+}
+
+// ToMatchResponse converts a match to a MatchResponse.
+func ToMatchResponse(m Match) MatchResponse {
+	leaderboard := m.Leaderboard()
+	scores := make([]MatchScoreResponse, len(leaderboard))
+	for i, s := range leaderboard {
+		rank := i
+		if i > 0 && s.Points == leaderboard[i-1].Points {
+			rank = scores[i-1].Rank // players with the same points share their rank
+		}
+		scores[i] = MatchScoreResponse{Rank: rank, Username: s.Username, Points: s.Points}
+	}
+	history := make([]RoundResponse, len(m.Played))
+	for i, r := range m.Played {
+		history[i] = RoundResponse{
+			Number:      r.Number,
+			GameID:      r.GameID,
+			CorrectWord: r.CorrectWord,
+			EndedAt:     r.EndedAt,
+		}
+	}
+	return MatchResponse{
+		ID:          m.ID,
+		Round:       m.Round(),
+		Rounds:      m.Rounds,
+		CreatedAt:   m.CreatedAt,
+		EndedAt:     m.EndedAt,
+		Leaderboard: scores,
+		History:     history,
+	}
+}
+
 // InitialData is the data sent to the client when a new connection is established
 // or when the game is started
 type InitialData struct {
 	Response
 	Active bool `json:"active"`
+	// Match is set when the room plays a match of several rounds
+	Match *MatchResponse `json:"match,omitempty"`
+	// Spectators is the number of spectators watching the game
+	Spectators int `json:"spectators"`
+	// Lobby is set until the game has started
+	Lobby *LobbyResponse `json:"lobby,omitempty"`
+	// ResumeToken is sent to players to resume their session if their connection drops
+	ResumeToken string `json:"resume_token,omitempty"`
+	// Reconnecting contains the players whose connection dropped and who can still resume their session
+	Reconnecting []string `json:"reconnecting,omitempty"`
+	// Seq is the sequence number of the last event broadcast in the room
+	Seq uint64 `json:"seq"`
+	// Version is the version of the protocol negotiated with the player
+	Version int `json:"version"`
+	// LeaderboardVersion is the version of the leaderboard in GamePerformance
+	LeaderboardVersion int `json:"leaderboard_version"`
+}
+
+// LobbyResponse contains the players waiting for the game to start
+type LobbyResponse struct {
+	Players []LobbyPlayerResponse `json:"players"`
+	// Countdown is the number of seconds left before the game starts, it is set once the countdown has started
+	Countdown *int `json:"countdown,omitempty"`
+}
+
+type LobbyPlayerResponse struct {
+	Username string `json:"username"`
+	Ready    bool   `json:"ready"`
+}
+
+// SpectatorsResponse notifies players when a spectator starts or stops watching the game
+type SpectatorsResponse struct {
+	// Count is the number of spectators watching the game
+	Count int `json:"count"`
+}
+
+// OwnerResponse notifies players when another player becomes the creator of the game
+type OwnerResponse struct {
+	Creator string `json:"creator"`
+}
+
+// ModerationResponse notifies players of a moderation action taken by the creator of the game
+type ModerationResponse struct {
+	Action    Action `json:"action"`
+	Moderator string `json:"moderator"`
+	Target    string `json:"target"`
 }
 
 func sorted[T any](x iter.Seq[T], fn func(a, b T) bool) iter.Seq[T] {
@@ -117,6 +271,7 @@ func ToResponse(g Game, username string) Response {
 		CorrectWord:     setWord(g.CorrectWord.Word),
 		Guesses:         guesses,
 		GamePerformance: perf,
+		Settings:        g.Rules(),
 		ID:              g.ID,
 	}
 }
@@ -138,9 +293,23 @@ func ToGuess(w word.Word, showWord bool) GuessResponse {
 }
 
 func ToLeaderboard(l RankBoard) LeaderboardResponse {
+	return toLeaderboard(l, l.Ranks)
+}
+
+// ToLeaderboardDelta returns the entries of the leaderboard changed by a guess of the user that displaced `offset` players,
+// the entries are the ones between the new rank of the user and his previous rank.
+func ToLeaderboardDelta(l RankBoard, username string, offset int) LeaderboardResponse {
+	rank, ok := l.Positions[username]
+	if !ok {
+		return LeaderboardResponse{}
+	}
+	return toLeaderboard(l, l.Ranks[rank:min(rank+offset+1, len(l.Ranks))])
+}
+
+func toLeaderboard(l RankBoard, sessions []*Session) LeaderboardResponse {
 	// copy the map, to prevent the original from being modified
-	m := make([]PlayerSummaryResponse, len(l.Ranks))
-	for i, v := range l.Ranks {
+	m := make([]PlayerSummaryResponse, len(sessions))
+	for i, v := range sessions {
 		m[i] = PlayerSummaryResponse{
 			Username:    v.Player.Username,
 			Best:        ToGuess(v.BestGuess(), false),
@@ -155,7 +324,8 @@ func ToLeaderboard(l RankBoard) LeaderboardResponse {
 // This function is called on game start and on new connection to the game
 func ToInitialData(g Game, username string) InitialData {
 	return InitialData{
-		Response: ToResponse(g, username),
-		Active:   g.IsActive(),
+		Response:           ToResponse(g, username),
+		Active:             g.IsActive(),
+		LeaderboardVersion: g.LeaderboardVersion(),
 	}
 }
@@ -54,7 +54,7 @@ func ExampleToResponse() {
 	}
 	b.Write(bytes)
 	fmt.Println(b.String())
-	// Output:{"created_at":"0001-01-01T00:00:00Z","started_at":"0001-01-01T00:00:00Z","ended_at":null,"creator":"","guesses":[{"word":"JAMES","played_at":"0001-01-01T00:00:00Z","status":[1,3,1,2,1]},{"word":"HALLO","played_at":"0001-01-01T00:00:00Z","status":[3,1,3,3,3]}],"game_performance":[{"rank":0,"best":{"played_at":"0001-01-01T00:00:00Z","status":[3,1,3,3,3]},"username":"test","words_played":2},{"rank":1,"best":{"played_at":"0001-01-01T00:00:00Z"},"username":"second_test","words_played":0}],"id":"00000000-0000-0000-0000-000000000000"}
+	// Output:{"created_at":"0001-01-01T00:00:00Z","started_at":"0001-01-01T00:00:00Z","ended_at":null,"creator":"","guesses":[{"word":"JAMES","played_at":"0001-01-01T00:00:00Z","status":[1,3,1,2,1]},{"word":"HALLO","played_at":"0001-01-01T00:00:00Z","status":[3,1,3,3,3]}],"game_performance":[{"rank":0,"best":{"played_at":"0001-01-01T00:00:00Z","status":[3,1,3,3,3]},"username":"test","words_played":2},{"rank":1,"best":{"played_at":"0001-01-01T00:00:00Z"},"username":"second_test","words_played":0}],"settings":{"mode":"classic","word_length":5,"max_guesses":6,"time_limit":3600,"lobby_timeout":3600,"hard_mode":false,"rounds":1,"auto_start":false,"countdown":0},"id":"00000000-0000-0000-0000-000000000000"}
 }
 
 func TestToGuess(t *testing.T) {
@@ -168,6 +168,18 @@ func TestToGame(t *testing.T) {
 	}
 }
 
+func TestToResponse_Settings(t *testing.T) {
+	g := New("test", word.New("GAMES"))
+	g.Settings.Mode = Sprint
+	g.Settings.TimeLimit = 600
+
+	got := ToResponse(*g, "test").Settings
+	assert.Equal(t, Sprint, got.Mode)
+	assert.Equal(t, Unlimited, got.MaxGuesses, "sprint games have no limit on guesses")
+	assert.Equal(t, 600, got.TimeLimit)
+	assert.Equal(t, word.DefaultLength, got.WordLength)
+}
+
 func TestToInitialData(t *testing.T) {
 	type args struct {
 		username string
@@ -232,3 +244,37 @@ func TestToInitialData(t *testing.T) {
 		})
 	}
 }
+
+func TestToMatchResponse(t *testing.T) {
+	m := Match{
+		Rounds: 3,
+		Played: []Round{{Number: 1, CorrectWord: "GAMES"}},
+		Scores: map[string]int{"fela": 2, "james": 3, "jane": 2, "john": 0},
+	}
+
+	got := ToMatchResponse(m)
+	assert.Equal(t, 2, got.Round)
+	assert.Equal(t, []MatchScoreResponse{
+		{Rank: 0, Username: "james", Points: 3},
+		{Rank: 1, Username: "fela", Points: 2},
+		{Rank: 1, Username: "jane", Points: 2},
+		{Rank: 3, Username: "john", Points: 0},
+	}, got.Leaderboard, "players with the same points share their rank")
+	assert.Equal(t, []RoundResponse{{Number: 1, CorrectWord: "GAMES"}}, got.History)
+}
+
+func TestToLeaderboardDelta(t *testing.T) {
+	g := sampleGame()
+	g.Leaderboard.Ranks[0], g.Leaderboard.Ranks[1] = g.Leaderboard.Ranks[1], g.Leaderboard.Ranks[0]
+	g.Leaderboard.Positions = map[string]int{"second_test": 0, "test": 1}
+
+	offset := g.Leaderboard.FixPosition("test")
+	require.Equal(t, 1, offset)
+	delta := ToLeaderboardDelta(g.Leaderboard, "test", offset)
+	assert.Equal(t, ToLeaderboard(g.Leaderboard), delta, "the entries of both displaced players are sent")
+
+	delta = ToLeaderboardDelta(g.Leaderboard, "test", 0)
+	require.Len(t, delta, 1)
+	assert.Equal(t, "test", delta[0].Username)
+	assert.Empty(t, ToLeaderboardDelta(g.Leaderboard, "unknown", 0))
+}
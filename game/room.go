@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +18,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/kodekulture/wordle-server/game/word"
+	"github.com/kodekulture/wordle-server/internal/config"
 )
 
 type Event string
@@ -26,12 +30,30 @@ const (
 	SPlay   Event = "server/play"
 	CPlay   Event = "client/play"
 	CFinish Event = "client/finish"
+	CRound  Event = "client/round"
+	CTick   Event = "client/tick"
 
 	SStart Event = "server/start"
 	CStart Event = "client/start"
 
-	CJoin  Event = "client/join"
-	CLeave Event = "client/leave"
+	SReady Event = "server/ready"
+	CLobby Event = "client/lobby"
+
+	SSync Event = "server/sync"
+
+	SLeaderboard Event = "server/leaderboard"
+	CLeaderboard Event = "client/leaderboard"
+
+	SKick       Event = "server/kick"
+	SBan        Event = "server/ban"
+	STransfer   Event = "server/transfer"
+	CModeration Event = "client/moderation"
+	COwner      Event = "client/owner"
+
+	CJoin         Event = "client/join"
+	CLeave        Event = "client/leave"
+	CReconnecting Event = "client/reconnecting"
+	CSpectators   Event = "client/spectators"
 
 	CData  Event = "client/data"
 	CError Event = "client/error"
@@ -40,6 +62,10 @@ const (
 	PLeave      Event = "private/leave"
 	PKickout    Event = "private/kickout"
 	PDisconnect Event = "private/disconnect"
+	PExpire     Event = "private/expire"
+	PQuery      Event = "private/query"
+	PClose      Event = "private/close"
+	PRelease    Event = "private/release"
 )
 
 type Payload struct {
@@ -47,6 +73,7 @@ type Payload struct {
 	Data   interface{} `json:"data"`
 	From   string      `json:"from"`          // From is the name of the player that sent the message displayed to all other players in the room
 	Key    string      `json:"key,omitempty"` // Key is optionally provided by clients for event deduplication. It has to be returned back to the client as is when applicable.
+	Seq    uint64      `json:"seq,omitempty"` // Seq is the sequence number of the events broadcast in the room, it increases with every broadcast event.
 	sender *PlayerConn // sender is the player that sent the message
 }
 
@@ -80,70 +107,259 @@ type Service interface {
 	StartGame(context.Context, *Game) error
 	WipeGameData(context.Context, uuid.UUID) error
 	ValidateWord(string) bool
-	AddGuess(context.Context, uuid.UUID, string, word.Word, bool) error
+	// AddGuess stores a guess of a player, the score of his session is stored when the guess is his best guess
+	AddGuess(ctx context.Context, gameID uuid.UUID, player string, guess word.Word, score float64, isBest bool) error
+	// GenerateWord returns a new word to guess with the given length
+	GenerateWord(int) string
+	// FinishRound stores a game that has ended as a round of the match
+	FinishRound(context.Context, *Match, *Game) error
+	// SaveMatch stores the scores of a match and the game of its next round, so that the room can be restored between rounds
+	SaveMatch(context.Context, *Match, *Game) error
+	// Moderate records a moderation action taken in a room
+	Moderate(context.Context, Moderation) error
+	// ChangeOwner stores the new creator of a game
+	ChangeOwner(context.Context, *Game) error
+	// SaveLobby stores the players of a game that has not started, so that its lobby can be restored
+	SaveLobby(context.Context, *Game) error
+	// DropLobby removes the stored lobby of a room that closed before its game started
+	DropLobby(context.Context, uuid.UUID) error
+	// AppendRecord appends a record to the log of a game, the game is rebuilt from its log with Replay
+	AppendRecord(ctx context.Context, gameID uuid.UUID, rec Record) error
+	// AddEvent stores an event broadcast in a room, so that it can be replayed when the room is restored
+	AddEvent(context.Context, uuid.UUID, Payload) error
+	// SaveSeq stores the sequence number of an event broadcast in a room that is not stored, such as a clock tick,
+	// so that a restored room does not hand out sequence numbers the players have already seen
+	SaveSeq(ctx context.Context, roomID uuid.UUID, seq uint64) error
 }
 
+// Room is owned by its event loop, the state of the room is only read and changed by the `run` goroutine.
+// Other goroutines send events to the room, or run queries on the event loop with `do`.
 type Room struct {
 	// This context is used to protect writes in room's closed channels
 	ctx       context.Context
 	cancelCtx func() // cancel the room's context
+	// stopped is closed when the event loop has returned, the state of the room does not change anymore
+	stopped chan struct{}
+
+	id      uuid.UUID // id is the ID of the first game played in the room
+	players map[string]*PlayerConn
+	// spectators receive the events of the room without playing the game
+	spectators map[string]*PlayerConn
+	broadcast  chan Payload
+	g          *Game
+	match      *Match          // match is set when the room plays several rounds
+	clock      *clock          // clock is set once the game has started
+	lobby      *lobby          // lobby is set until the game has started
+	banned     map[string]bool // banned contains the players who can not join the room anymore
+	// handoff fires when the creator has been away from the room for longer than ownerGrace
+	handoff *time.Timer
+	// orphaned is true when the creator is away and no player could be promoted, the next player to join is promoted
+	orphaned bool
+	// away contains the players whose connection dropped until they resume their session or the grace window expires
+	away map[string]*away
+	// seq is the sequence number of the last event broadcast in the room, history contains the last events
+	seq     uint64
+	history *history
+	// events queues the events to store, they are stored by storeEvents so that the event loop only waits for the store when the queue is full
+	events chan Payload
 
-	players   map[string]*PlayerConn
-	broadcast chan Payload
-	g         *Game
+	active bool        // whether the game has started
+	closed atomic.Bool // whether the game has finished
 
-	active bool // whether the game has started
-	closed bool // whether the game has finished
+	// limiters limit the rate of the messages sent by every player, a player keeps their limiter when they connect again.
+	// They are used by the read goroutines of the players, not by the event loop.
+	limitersMu sync.Mutex
+	limiters   map[string]*limiter
 
 	gs Service
 }
 
-// ID returns the ID of the room which is the ID of the game
+// ID returns the ID of the room which is the ID of its first game
 func (r *Room) ID() string {
-	return r.g.ID.String()
+	return r.id.String()
 }
 
-// Game returns the game of the room
+// Game returns a copy of the game currently played in the room
 func (r *Room) Game() *Game {
-	return r.g
+	var g *Game
+	r.do(func() { g = r.g.Clone() })
+	return g
+}
+
+// ConnOption configures the connection of a player to a room
+type ConnOption func(*PlayerConn)
+
+// WithVersion sets the version of the protocol negotiated with the player
+func WithVersion(version int) ConnOption {
+	return func(p *PlayerConn) { p.version = version }
+}
+
+// WithCodec sets the codec negotiated with the player
+func WithCodec(c Codec) ConnOption {
+	return func(p *PlayerConn) { p.codec = c }
 }
 
 // Join adds a player to the room
-func (r *Room) Join(p Player, conn *websocket.Conn) {
-	pc := newPlayerConn(conn, r, p)
-	r.tryBroadcast(newPayload(PJoin, pc))
+func (r *Room) Join(p Player, conn *websocket.Conn, opts ...ConnOption) {
+	r.enter(newPlayerConn(conn, r, p, false, opts...))
 }
 
-// CanJoin checks if a player can join the room
-func (r *Room) CanJoin(username string) error {
-	if r.IsClosed() {
-		return errors.New("the room is closed")
+// Resume adds a player whose connection dropped back to the room, the messages he missed are replayed
+// if the token is the resume token he received with his data.
+func (r *Room) Resume(p Player, conn *websocket.Conn, token string, opts ...ConnOption) {
+	pc := newPlayerConn(conn, r, p, false, opts...)
+	pc.resume = token
+	r.enter(pc)
+}
+
+// CanResume checks if `token` is the resume token of the last connection of a player, the player can then resume their session
+// without a new invite.
+func (r *Room) CanResume(username, token string) bool {
+	var ok bool
+	r.do(func() {
+		ok = r.canResume(username, token)
+	})
+	return ok
+}
+
+// canResume returns true when `token` is the resume token of the connection of a player who is in the room or away.
+func (r *Room) canResume(username, token string) bool {
+	if token == "" {
+		return false
 	}
-	_, ok := r.g.Sessions[username]
-	if r.active && !ok {
-		return errors.New("the game has already started")
+	old, gone := r.players[username], r.away[username]
+	return (gone != nil && gone.token == token) || (old != nil && old.token == token)
+}
+
+// Spectate adds a spectator to the room
+func (r *Room) Spectate(p Player, conn *websocket.Conn, opts ...ConnOption) {
+	r.enter(newPlayerConn(conn, r, p, true, opts...))
+}
+
+// enter sends the connection to the room, it is closed when the room is closed already.
+func (r *Room) enter(pc *PlayerConn) {
+	if !r.tryBroadcast(newPayload(PJoin, pc)) {
+		pc.drop(websocket.CloseGoingAway, "the room is closed")
 	}
-	return nil
+}
+
+// CanJoin checks if a player can join the room
+func (r *Room) CanJoin(username string) error {
+	var err error
+	r.do(func() {
+		_, ok := r.g.Sessions[username]
+		switch {
+		case r.closed.Load():
+			err = errors.New("the room is closed")
+		case r.banned[username]:
+			err = errors.New("you have been banned from the room")
+		case r.active && !ok:
+			err = errors.New("the game has already started")
+		case r.match != nil && r.match.HasStarted() && !r.match.IsMember(username):
+			err = errors.New("the match has already started")
+		}
+	})
+	return err
+}
+
+// CanSpectate checks if the room can be watched by a spectator
+func (r *Room) CanSpectate(username string) error {
+	var err error
+	r.do(func() {
+		switch {
+		case r.closed.Load():
+			err = errors.New("the room is closed")
+		case r.banned[username]:
+			err = errors.New("you have been banned from the room")
+		}
+	})
+	return err
 }
 
 // IsClosed checks if the room is closed
 func (r *Room) IsClosed() bool {
-	return r.closed
+	return r.closed.Load()
+}
+
+// do runs fn on the event loop of the room and waits for it to return.
+// Once the event loop has stopped, the state of the room does not change and fn runs on the calling goroutine.
+func (r *Room) do(fn func()) {
+	done := make(chan struct{})
+	query := func() {
+		defer close(done)
+		fn()
+	}
+	select {
+	case r.broadcast <- newPayload(PQuery, query):
+		<-done
+	case <-r.stopped:
+		fn()
+	}
 }
 
 // NewRoom creates a new room and add it to the Hub.
 func NewRoom(game *Game, gs Service) *Room {
+	return RestoreRoom(game, nil, 0, gs)
+}
+
+// RestoreRoom creates a room with the events that were broadcast in it before, the events are ordered by their sequence number.
+// `seq` is the last sequence number handed out in the room, the sequence continues from it or from the last event if it is greater.
+// A game that ended before it was stored is stored when the room is restored, the room is closed unless the match has more rounds.
+func RestoreRoom(game *Game, events []Payload, seq uint64, gs Service) *Room {
+	var match *Match
+	if game.Rules().Rounds > 1 {
+		match = NewMatch(game)
+	}
+	return restoreRoom(game.ID, match, game, events, seq, gs)
+}
+
+// RestoreMatch creates the room of a match whose current round is `game`, with the events that were broadcast in the room before.
+func RestoreMatch(m *Match, game *Game, events []Payload, seq uint64, gs Service) *Room {
+	return restoreRoom(m.ID, m, game, events, seq, gs)
+}
+
+func restoreRoom(id uuid.UUID, match *Match, game *Game, events []Payload, seq uint64, gs Service) *Room {
 	ctx, cancel := context.WithCancel(context.Background())
 	room := &Room{
-		ctx:       ctx,
-		cancelCtx: cancel,
-		players:   make(map[string]*PlayerConn),
-		broadcast: make(chan Payload),
-		g:         game,
-		gs:        gs,
+		ctx:        ctx,
+		cancelCtx:  cancel,
+		stopped:    make(chan struct{}),
+		id:         id,
+		match:      match,
+		players:    make(map[string]*PlayerConn),
+		spectators: make(map[string]*PlayerConn),
+		banned:     make(map[string]bool),
+		away:       make(map[string]*away),
+		limiters:   make(map[string]*limiter),
+		history:    newHistory(MaxHistory),
+		events:     make(chan Payload, MaxHistory),
+		broadcast:  make(chan Payload),
+		g:          game,
+		gs:         gs,
+		seq:        seq,
 
 		active: game.StartedAt != nil && game.EndedAt == nil,
-		closed: game.EndedAt != nil,
+	}
+	if room.active {
+		room.clock = newClock(game.Deadline())
+	}
+	if game.StartedAt == nil {
+		room.lobby = newLobby()
+	}
+	for _, e := range events {
+		room.history.add(e)
+		room.seq = max(room.seq, e.Seq)
+	}
+	if gs != nil {
+		go room.storeEvents()
+	}
+	// the server stopped before the ended game was stored, the room is closed unless the match has more rounds
+	if game.EndedAt != nil {
+		if gs == nil {
+			room.closed.Store(true)
+		} else {
+			room.finishRound()
+		}
 	}
 	go room.run()
 	return room
@@ -153,36 +369,102 @@ func NewRoom(game *Game, gs Service) *Room {
 func (r *Room) start(m Payload) {
 	pconn := m.sender
 	// Check if the player is the creator of the game
-	if r.g.Creator != m.From {
-		m.sender.write(newPayload(CError, "Only the game's creator can start the game", withKey(m.Key)))
+	if r.g.Creator != m.From || pconn.spectator {
+		m.sender.fail(m.Key, ErrCodeNotCreator, "Only the game's creator can start the game")
 		return
 	}
 	// Check if the game has already started
 	if r.active {
-		m.sender.write(newPayload(CError, "Game already started", withKey(m.Key)))
+		m.sender.fail(m.Key, ErrCodeAlreadyStarted, "Game already started")
+		return
+	}
+	// The creator is ready when he starts the game, but the other players must be ready too
+	r.lobby.setReady(pconn.PName(), true)
+	if !r.lobby.allReady() {
+		r.sendAll(newPayload(CLobby, r.lobby.response()))
+		m.sender.fail(m.Key, ErrCodeNotReady, "Not every player is ready")
 		return
 	}
-	r.g.Start()
-	// Save the game to the database, so users can jump back in
+	if err := r.begin(); err != nil {
+		m.sender.fail(m.Key, ErrCodeInternal, "Failed to start game")
+	}
+}
+
+// begin starts the game and broadcasts a `CStart` event and the game data to all players in the room.
+func (r *Room) begin() error {
+	now := time.Now()
+	// Save the game to the database, so users can jump back in. The room stays in its lobby when the game is not saved.
 	if r.gs != nil {
-		err := r.gs.StartGame(r.ctx, r.g)
-		if err != nil {
-			m.sender.write(newPayload(CError, "Failed to start game", withKey(m.Key)))
-			return
+		started := r.g.Clone()
+		started.startAt(now)
+		if err := r.gs.StartGame(r.ctx, started); err != nil {
+			return err
 		}
 	}
+	if err := r.record(startRecord(now)); err != nil {
+		return err
+	}
+	r.g.startAt(now)
 	r.active = true
+	r.clock = newClock(r.g.Deadline())
+	r.lobby.stop()
+	r.lobby = nil
 	r.sendAll(newPayload(CStart, "Game started!"))
-	r.sendAll(newPayload(CData, ToInitialData(ptr.ToObj(r.g), pconn.PName())))
+	r.sendData()
+	return nil
 }
 
-// message process `SMessage` event and broadcasts a `CMessage` event to all players in the room.
-func (r *Room) message(m Payload) {
-	text, ok := m.Data.(string)
-	if !ok {
-		m.sender.write(newPayload(CError, "Invalid message type", withKey(m.Key)))
+// ready process `SReady` event and broadcasts a `CLobby` event to all players in the room.
+// The data of the event is false when the player is not ready anymore.
+func (r *Room) ready(m Payload) {
+	if m.sender.spectator {
+		m.sender.fail(m.Key, ErrCodeSpectator, "Spectators can not play")
+		return
+	}
+	if r.active {
+		m.sender.fail(m.Key, ErrCodeAlreadyStarted, "Game already started")
 		return
 	}
+	ready := bool(m.Data.(ReadyRequest))
+	r.lobby.setReady(m.sender.PName(), ready)
+	if countdown := r.g.Rules().CountdownDuration(); ready && countdown > 0 {
+		r.lobby.startCountdown(countdown)
+	}
+	r.sendAll(newPayload(CLobby, r.lobby.response(), withKey(m.Key)))
+	r.tryAutoStart()
+}
+
+// tryAutoStart starts the game if it is started automatically and every player in the lobby is ready.
+func (r *Room) tryAutoStart() {
+	if r.lobby == nil || !r.g.Rules().AutoStart || !r.lobby.allReady() {
+		return
+	}
+	r.autoStart()
+}
+
+// autoStart starts the game without waiting for its creator.
+// When the game fails to start, the countdown of the lobby starts again so that the room does not stay in its lobby.
+func (r *Room) autoStart() {
+	if err := r.begin(); err != nil {
+		log.Err(err).Caller().Msg("failed to start game")
+		r.sendAll(newPayload(CError, ErrorResponse{Code: ErrCodeInternal, Message: "Failed to start game"}))
+		r.lobby.restartCountdown(startRetry)
+		r.sendAll(newPayload(CLobby, r.lobby.response()))
+	}
+}
+
+// sendData sends every player in the room his current state in the game.
+func (r *Room) sendData() {
+	for _, conns := range []map[string]*PlayerConn{r.players, r.spectators} {
+		for _, p := range conns {
+			p.write(newPayload(CData, r.initialData(p)))
+		}
+	}
+}
+
+// message process `SMessage` event and broadcasts a `CMessage` event to all players in the room.
+func (r *Room) message(m Payload) {
+	text := string(m.Data.(MessageRequest))
 	r.sendAll(newPayload(CMessage, text, withFrom(m.From)))
 }
 
@@ -191,42 +473,41 @@ var letterRegexp = regexp.MustCompile("^[a-zA-Z]+$")
 // play Process `SPlay` event and broadcasts a `CPlay` event to all players in the room
 // and `CResult` event to the player who submitted the message.
 func (r *Room) play(m Payload) {
+	if m.sender.spectator {
+		m.sender.fail(m.Key, ErrCodeSpectator, "Spectators can not play")
+		return
+	}
 	// If the game has not started, return an error
 	if !r.active {
-		m.sender.write(newPayload(CError, "Room isn't active", withKey(m.Key)))
+		m.sender.fail(m.Key, ErrCodeNotActive, "Room isn't active")
 		return
 	}
 	session := r.g.Sessions[m.sender.PName()]
 	// If the user is not in the game, return an error
 	if session == nil {
-		m.sender.write(newPayload(CError, "Invalid user session", withKey(m.Key)))
+		m.sender.fail(m.Key, ErrCodeNoSession, "Invalid user session")
 		return
 	}
 	// Check if the user already won
 	if session.Won() {
-		m.sender.write(newPayload(CError, "You already won", withKey(m.Key)))
+		m.sender.fail(m.Key, ErrCodeAlreadyWon, "You already won")
 		return
 	}
 	// Check if the user already used all their attempts or won
 	if !session.CanPlay() {
-		m.sender.write(newPayload(CError, "You already used all your attempts", withKey(m.Key)))
-		return
-	}
-	// Parse message and send error if type is not string
-	text, ok := m.Data.(string)
-	if !ok {
-		m.sender.write(newPayload(CError, "Invalid message", withKey(m.Key)))
+		m.sender.fail(m.Key, ErrCodeNoAttempts, "You already used all your attempts")
 		return
 	}
+	text := string(m.Data.(PlayRequest))
 
 	// Check given word length
-	if len(text) != word.Length {
-		m.sender.write(newPayload(CError, "Invalid message string length", withKey(m.Key)))
+	if len(text) != r.g.WordLength() {
+		m.sender.fail(m.Key, ErrCodeInvalidLength, "Invalid message string length")
 		return
 	}
 	// Check if the given word is valid
 	if !letterRegexp.MatchString(text) {
-		m.sender.write(newPayload(CError, "Invalid message characters", withKey(m.Key)))
+		m.sender.fail(m.Key, ErrCodeInvalidCharacters, "Invalid message characters")
 		return
 	}
 
@@ -234,17 +515,35 @@ func (r *Room) play(m Payload) {
 	w := word.New(text)
 
 	if isEnglishWord := r.gs.ValidateWord(w.Word); !isEnglishWord {
-		m.sender.write(newPayload(CError, "Invalid english word", withKey(m.Key)))
+		m.sender.fail(m.Key, ErrCodeInvalidWord, "Invalid english word")
 		return
 	}
 
-	dRank, usersBest, err := r.g.Play(m.sender.PName(), &w)
-	if err != nil {
-		m.sender.write(newPayload(CError, err.Error(), withKey(m.Key)))
+	// The guess is written to the log before it is played, so that it is not lost if the server stops
+	now := time.Now().UTC()
+	if err := r.record(guessRecord(m.sender.PName(), w.Word, now)); err != nil {
+		m.sender.fail(m.Key, ErrCodeInternal, "Failed to store guess")
+		return
+	}
+	dRank, usersBest, err := r.g.playAt(m.sender.PName(), &w, now)
+	var hintErr *word.HintError
+	switch {
+	case errors.As(err, &hintErr):
+		hint := NewHintErrorResponse(hintErr)
+		m.sender.write(newPayload(CError, ErrorResponse{Code: ErrCodeHintNotUsed, Message: hint.Message, Hint: &hint}, withKey(m.Key)))
+		return
+	case errors.Is(err, ErrPlayerNotFound):
+		m.sender.fail(m.Key, ErrCodeNoSession, err.Error())
+		return
+	case errors.Is(err, ErrSessionEnded):
+		m.sender.fail(m.Key, ErrCodeNoAttempts, err.Error())
+		return
+	case err != nil:
+		m.sender.fail(m.Key, ErrCodeInternal, err.Error())
 		return
 	}
 
-	if err = r.gs.AddGuess(r.ctx, r.g.ID, m.sender.PName(), w, usersBest); err != nil {
+	if err = r.gs.AddGuess(r.ctx, r.g.ID, m.sender.PName(), w, r.g.Score(m.sender.PName()), usersBest); err != nil {
 		log.Err(err).Caller().Msg("failed to store guess")
 	}
 
@@ -252,31 +551,156 @@ func (r *Room) play(m Payload) {
 	result := PlayerGuessResponse{
 		Result:      ToGuess(w, false),
 		RankOffset:  ptr.Obj(dRank),
-		Leaderboard: ToLeaderboard(r.g.Leaderboard),
+		Leaderboard: ToLeaderboardDelta(r.g.Leaderboard, m.sender.PName(), dRank),
+		Version:     r.g.LeaderboardVersion(),
+		snapshot:    ToLeaderboard(r.g.Leaderboard),
 	}
 	r.sendAll(newPayload(CPlay, result, withFrom(m.From), withKey(m.Key)))
 
-	// Check if the game has finished, if so, saveAndClose the room
+	// Check if the game has finished, if so, end the round
 	if r.g.HasEnded() {
-		r.sendAll(newPayload(CFinish, "Game has ended"))
-		r.Close()
+		r.endRound("Game has ended")
 	}
 }
 
+// sync process `SSync` event and replays the events broadcast after the sequence number sent by the player.
+// The player receives his current state in a `CData` event instead when some of these events are not kept anymore.
+func (r *Room) sync(m Payload) {
+	events, ok := r.history.since(uint64(m.Data.(SyncRequest)))
+	if !ok {
+		m.sender.write(newPayload(CData, r.initialData(m.sender), withKey(m.Key)))
+		return
+	}
+	for _, e := range events {
+		if err := m.sender.write(e); err != nil {
+			return
+		}
+	}
+}
+
+// leaderboard process `SLeaderboard` event and sends the whole leaderboard to the player who asked for it.
+func (r *Room) leaderboard(m Payload) {
+	snapshot := LeaderboardSnapshotResponse{
+		Version:     r.g.LeaderboardVersion(),
+		Leaderboard: ToLeaderboard(r.g.Leaderboard),
+	}
+	m.sender.write(newPayload(CLeaderboard, snapshot, withKey(m.Key)))
+}
+
+// tick broadcasts a `CTick` event with the time left in the game to all players in the room.
+func (r *Room) tick() {
+	r.sendAll(newPayload(CTick, TickResponse{Remaining: int(r.clock.remaining().Seconds())}))
+}
+
+// timeout ends the game when its time limit is reached and broadcasts a `CFinish` event to all players in the room.
+func (r *Room) timeout() {
+	r.g.Expire()
+	r.endRound("Time is up")
+}
+
+// endRound broadcasts a `CFinish` event when the game has ended and closes the room unless
+// there are more rounds to play in the match.
+// Between rounds the scores of the match are broadcast in a `CRound` event and the next game waits to be started.
+func (r *Room) endRound(reason string) {
+	r.record(finishRecord(r.g))
+	r.sendAll(newPayload(CFinish, reason))
+	r.finishRound()
+}
+
+// finishRound stores a game that has ended, it is also used to finish the ended game of a restored room.
+func (r *Room) finishRound() {
+	if r.match == nil {
+		r.close()
+		return
+	}
+	r.active = false
+	r.clock.stop()
+	r.clock = nil
+
+	r.match.Score(r.g)
+	if err := r.gs.FinishRound(r.ctx, r.match, r.g); err != nil {
+		log.Err(err).Caller().Msg("failed to store round")
+	}
+	r.sendAll(newPayload(CRound, ToMatchResponse(ptr.ToObj(r.match))))
+	if r.match.HasEnded() {
+		r.close()
+		return
+	}
+	r.g = r.match.NextRound(r.g, word.New(r.gs.GenerateWord(r.g.WordLength())))
+	// players banned during the round do not play the next rounds
+	for username := range r.banned {
+		r.g.Leave(username)
+	}
+	r.record(CreateRecord(r.g))
+	for _, s := range r.g.Sessions {
+		r.record(joinRecord(s.Player))
+	}
+	if err := r.gs.SaveMatch(r.ctx, r.match, r.g); err != nil {
+		log.Err(err).Caller().Msg("failed to store match")
+	}
+	r.lobby = newLobby()
+	for username := range r.players {
+		r.lobby.join(username)
+	}
+	r.sendData()
+}
+
+// initialData returns the state of the room for a player
+func (r *Room) initialData(p *PlayerConn) InitialData {
+	data := ToInitialData(ptr.ToObj(r.g), p.PName())
+	if !p.spectator {
+		data.ResumeToken = p.token
+	}
+	data.Seq = r.seq
+	data.Version = p.version
+	data.Spectators = len(r.spectators)
+	for username := range r.away {
+		data.Reconnecting = append(data.Reconnecting, username)
+	}
+	slices.Sort(data.Reconnecting)
+	if r.lobby != nil {
+		data.Lobby = ptr.Obj(r.lobby.response())
+	}
+	if r.match != nil {
+		data.Match = ptr.Obj(ToMatchResponse(ptr.ToObj(r.match)))
+	}
+	return data
+}
+
 func (r *Room) join(m Payload) {
 	pconn := m.Data.(*PlayerConn)
-	old := r.players[pconn.PName()]
+	if pconn.spectator {
+		r.spectate(pconn)
+		return
+	}
+	old, gone := r.players[pconn.PName()], r.away[pconn.PName()]
+	// The player resumes his session if he has the resume token of his last connection
+	resumed := r.canResume(pconn.PName(), pconn.resume)
 	// If the player is already in the room, kick him out.
-	if old != nil {
+	switch {
+	case old != nil && resumed:
+		old.close()
+		delete(r.players, old.PName())
+	case old != nil:
 		r.leave(newPayload(PKickout, old))
 	}
+	if gone != nil {
+		gone.timer.Stop()
+		delete(r.away, pconn.PName())
+	}
+	pconn.token = newResumeToken()
 	// Create a new session for the user if it doesn't exist.
 	if _, ok := r.g.Sessions[pconn.PName()]; !ok {
 		r.g.Join(pconn.player)
+		r.record(joinRecord(pconn.player))
+		r.saveLobby()
+	}
+	if r.lobby != nil {
+		r.lobby.join(pconn.PName())
 	}
 	// Send the player his current state in the game.
 	// On error, saveAndClose the player connection since he will have inconsistent data with which he can't play the game.
-	err := pconn.write(newPayload(CData, ToInitialData(ptr.ToObj(r.g), pconn.PName())))
+	err := pconn.write(newPayload(CData, r.initialData(pconn)))
 	if err != nil {
 		log.Err(err).Caller().Msg("failed to send player data")
 		err = pconn.close()
@@ -286,11 +710,50 @@ func (r *Room) join(m Payload) {
 		return
 	}
 	r.players[pconn.PName()] = pconn
-	r.sendAll(newPayload(CJoin, fmt.Sprintf("%s has joined", pconn.PName()), withFrom(pconn.PName())))
+	if resumed && gone != nil {
+		for _, p := range gone.missed {
+			pconn.write(p)
+		}
+	}
+	text := fmt.Sprintf("%s has joined", pconn.PName())
+	if resumed {
+		text = fmt.Sprintf("%s has reconnected", pconn.PName())
+	}
+	r.sendAll(newPayload(CJoin, text, withFrom(pconn.PName())))
+	if r.lobby != nil {
+		r.sendAll(newPayload(CLobby, r.lobby.response()))
+	}
+	switch {
+	case pconn.PName() == r.g.Creator:
+		r.stopHandoff()
+	case r.orphaned:
+		r.transfer(pconn.PName())
+	}
+}
+
+// spectate adds a spectator to the room without creating a session for him and broadcasts
+// a `CSpectators` event with the new number of spectators to all players in the room.
+func (r *Room) spectate(pconn *PlayerConn) {
+	// If the spectator is already watching the game, kick him out.
+	if old := r.spectators[pconn.PName()]; old != nil {
+		r.leave(newPayload(PKickout, old))
+	}
+	r.spectators[pconn.PName()] = pconn
+	err := pconn.write(newPayload(CData, r.initialData(pconn)))
+	if err != nil {
+		log.Err(err).Caller().Msg("failed to send spectator data")
+		delete(r.spectators, pconn.PName())
+		if err = pconn.close(); err != nil {
+			log.Err(err).Caller().Msg("failed to close spectator connection")
+		}
+		return
+	}
+	r.sendAll(newPayload(CSpectators, SpectatorsResponse{Count: len(r.spectators)}))
 }
 
 // leave process `SLeave` and `SKickout` events and broadcasts a `CLeave` event to all players in the room.
 // Also the player connection is closed and  is removed from the room (if the current user is the same as the player being kicked out).
+// A player whose connection dropped is reconnecting until the grace window expires, a `CReconnecting` event is broadcast instead.
 func (r *Room) leave(m Payload) {
 	var players []*PlayerConn
 	switch m.Data.(type) {
@@ -312,47 +775,236 @@ func (r *Room) leave(m Payload) {
 			continue
 		}
 		p.close()
-		delete(r.players, p.PName())
+		if p.spectator {
+			delete(r.spectators, p.PName())
+		} else {
+			delete(r.players, p.PName())
+		}
 	}
 	for _, p := range players {
 		// `p` is nil if and only if the player has already been kicked out
 		if p == nil {
 			continue
 		}
-		var text string
+		if p.spectator {
+			r.sendAll(newPayload(CSpectators, SpectatorsResponse{Count: len(r.spectators)}))
+			continue
+		}
 		if m.Type == PKickout {
-			text = fmt.Sprintf("%s has been kicked out", p.PName())
-		} else { // PLeave
-			text = fmt.Sprintf("%s has left", p.PName())
+			r.gone(p.PName(), fmt.Sprintf("%s has been kicked out", p.PName()))
+			continue
+		}
+		// PLeave
+		if reconnectGrace <= 0 {
+			r.gone(p.PName(), fmt.Sprintf("%s has left", p.PName()))
+			continue
 		}
-		r.sendAll(newPayload(CLeave, text))
+		r.disconnect(p)
 	}
 }
 
-// Close closes the room and all players in the room.
-// This is used when the game is finished.
-func (r *Room) Close() {
-	if r.closed {
+// disconnect keeps the session of a player whose connection dropped until the grace window expires,
+// and broadcasts a `CReconnecting` event to all players in the room.
+func (r *Room) disconnect(p *PlayerConn) {
+	token := p.token
+	r.away[p.PName()] = &away{
+		token: token,
+		timer: time.AfterFunc(reconnectGrace, func() {
+			r.tryBroadcast(newPayload(PExpire, token))
+		}),
+	}
+	r.sendAll(newPayload(CReconnecting, fmt.Sprintf("%s is reconnecting", p.PName()), withFrom(p.PName())))
+}
+
+// expire process `PExpire` event sent when the grace window of a player expires, the player has left
+// unless he has resumed his session.
+func (r *Room) expire(m Payload) {
+	token, _ := m.Data.(string)
+	for username, a := range r.away {
+		if a.token == token {
+			delete(r.away, username)
+			r.gone(username, fmt.Sprintf("%s has left", username))
+			return
+		}
+	}
+}
+
+// gone broadcasts a `CLeave` event when a player has left the room and removes him from the lobby.
+func (r *Room) gone(username, text string) {
+	r.sendAll(newPayload(CLeave, text))
+	if username == r.g.Creator && r.handoff == nil {
+		r.handoff = time.NewTimer(ownerGrace)
+	}
+	if r.lobby != nil {
+		r.lobby.leave(username)
+		r.saveLobby()
+		r.sendAll(newPayload(CLobby, r.lobby.response()))
+		r.tryAutoStart()
+	}
+}
+
+// record appends a record to the log of the game.
+func (r *Room) record(rec Record) error {
+	err := r.gs.AppendRecord(r.ctx, r.g.ID, rec)
+	if err != nil {
+		log.Err(err).Caller().Str("record", string(rec.Kind)).Msg("failed to append to the log of the game")
+	}
+	return err
+}
+
+// saveLobby stores the players of the game while the room is in its lobby.
+func (r *Room) saveLobby() {
+	if r.lobby == nil {
 		return
 	}
-	r.closed = true
-	r.active = false
-	// Cancel the context to stop the `leave` goroutine and saveAndClose
-	// all prevent any new players from sending messages to the room.
-	r.cancelCtx()
-	// Close all players connection
+	if err := r.gs.SaveLobby(r.ctx, r.g); err != nil {
+		log.Err(err).Caller().Msg("failed to store the lobby")
+	}
+}
+
+// transfer makes a player the creator of the game, stores the change and broadcasts a `COwner` event to all players in the room.
+func (r *Room) transfer(username string) {
+	r.g.Creator = username
+	r.record(ownerRecord(username))
+	r.stopHandoff()
+	r.orphaned = false
+	if err := r.gs.ChangeOwner(r.ctx, r.g); err != nil {
+		log.Err(err).Caller().Msg("failed to store the creator of the game")
+	}
+	r.sendAll(newPayload(COwner, OwnerResponse{Creator: username}))
+}
+
+// promote makes the player connected for the longest time the creator of the game when the creator has been away
+// for longer than ownerGrace. If there is no player to promote, the room is orphaned until a player joins.
+func (r *Room) promote() {
+	r.handoff = nil
+	if r.players[r.g.Creator] != nil {
+		return
+	}
+	var next *PlayerConn
 	for _, p := range r.players {
-		p.close()
-		delete(r.players, p.PName())
+		if next == nil || p.connectedAt.Before(next.connectedAt) {
+			next = p
+		}
+	}
+	if next == nil {
+		r.orphaned = true
+		return
+	}
+	r.transfer(next.PName())
+}
+
+// handoffExpired returns the channel of the hand-off timer.
+// It returns a nil channel, which blocks forever, when the creator is in the room.
+func (r *Room) handoffExpired() <-chan time.Time {
+	if r.handoff == nil {
+		return nil
+	}
+	return r.handoff.C
+}
+
+// stopHandoff stops the hand-off timer, it is called when the creator comes back.
+func (r *Room) stopHandoff() {
+	if r.handoff == nil {
+		return
+	}
+	r.handoff.Stop()
+	r.handoff = nil
+}
+
+// moderate process `SKick`, `SBan` and `STransfer` events sent by the creator of the game with the username of
+// the targeted player and broadcasts a `CModeration` event to all players in the room.
+// Kicked and banned players are removed from the room, their session is removed too if the game has not started.
+func (r *Room) moderate(m Payload, action Action) {
+	if r.g.Creator != m.From || m.sender.spectator {
+		m.sender.fail(m.Key, ErrCodeNotCreator, "Only the game's creator can moderate the room")
+		return
+	}
+	target := string(m.Data.(ModerationRequest))
+	if target == m.From {
+		m.sender.fail(m.Key, ErrCodeInvalidTarget, "You can not moderate yourself")
+		return
+	}
+	player, spectator := r.players[target], r.spectators[target]
+	_, hasSession := r.g.Sessions[target]
+	switch {
+	case action == ActionTransfer && player == nil:
+		m.sender.fail(m.Key, ErrCodeInvalidTarget, "The ownership can only be transferred to a connected player")
+		return
+	case player == nil && spectator == nil && !hasSession:
+		m.sender.fail(m.Key, ErrCodeUnknownPlayer, "Player is not in the room")
+		return
+	}
+
+	// every player, including the target, is told about the action before the target is removed
+	r.sendAll(newPayload(CModeration, ModerationResponse{Action: action, Moderator: m.From, Target: target}, withKey(m.Key)))
+	switch action {
+	case ActionTransfer:
+		r.transfer(target)
+	case ActionBan:
+		r.banned[target] = true
+		fallthrough
+	case ActionKick:
+		var conns []*PlayerConn
+		for _, p := range []*PlayerConn{player, spectator} {
+			if p != nil {
+				conns = append(conns, p)
+			}
+		}
+		if !r.active && hasSession {
+			r.g.Leave(target)
+			r.record(leaveRecord(target))
+		}
+		r.leave(newPayload(PKickout, conns))
+		// a player who is reconnecting can not resume his session anymore
+		if a := r.away[target]; a != nil {
+			a.timer.Stop()
+			delete(r.away, target)
+			r.gone(target, fmt.Sprintf("%s has been kicked out", target))
+		}
+	}
+
+	err := r.gs.Moderate(r.ctx, Moderation{
+		GameID:    r.g.ID,
+		Action:    action,
+		Moderator: m.From,
+		Target:    target,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Err(err).Caller().Msg("failed to store moderation")
+	}
+}
+
+// Close closes the room and the connections of its players on the event loop of the room and waits for the event loop to stop.
+// It is used to close rooms that are not played anymore and can be called many times.
+func (r *Room) Close() {
+	r.tryBroadcast(newPayload(PClose, nil))
+	<-r.stopped
+}
+
+// Release closes the room without storing its game and waits for the event loop to stop, it is used when another instance
+// runs the room. The players are disconnected with CloseServiceRestart, so that they connect again to the instance running the room.
+func (r *Room) Release() {
+	r.tryBroadcast(newPayload(PRelease, nil))
+	<-r.stopped
+}
+
+// close closes the connections of the room and stores its game, the event loop stops once the room is closed.
+func (r *Room) close() {
+	if !r.stop(websocket.CloseNormalClosure, "") {
+		return
 	}
-	r.broadcast = nil // nil channel will prevent send while closing it will cause panics
 
 	// Store the game in the database
 	if r.gs != nil && r.g.StartedAt != nil {
 		// it's either game has started but got abandoned or game actually finished
 		var err error
 		if r.g.HasEnded() {
-			err = r.gs.FinishGame(context.Background(), r.g)
+			// the last round of a match has been stored already
+			if r.match == nil {
+				err = r.gs.FinishGame(context.Background(), r.g)
+			}
 		} else {
 			err = r.gs.WipeGameData(context.Background(), r.g.ID)
 		}
@@ -361,15 +1013,66 @@ func (r *Room) Close() {
 			log.Err(err).Caller().Msg("failed to store game")
 		}
 	}
+	if r.gs != nil && r.g.StartedAt == nil {
+		if err := r.gs.DropLobby(context.Background(), r.g.ID); err != nil {
+			log.Err(err).Caller().Msg("failed to drop lobby")
+		}
+	}
+}
+
+// release closes the connections of a room run by another instance, its game is stored by that instance.
+func (r *Room) release() {
+	r.stop(websocket.CloseServiceRestart, "the room is run by another server")
+}
+
+// stop closes the connections of the room with the close `code`, the event loop stops once the room is stopped.
+// It returns false when the room was stopped already.
+func (r *Room) stop(code int, reason string) bool {
+	if r.closed.Load() {
+		return false
+	}
+	r.closed.Store(true)
+	r.active = false
+	r.clock.stop()
+	r.lobby.stop()
+	r.stopHandoff()
+	for _, a := range r.away {
+		a.timer.Stop()
+	}
+	// Cancel the context to stop the `leave` goroutine and saveAndClose
+	// all prevent any new players from sending messages to the room.
+	r.cancelCtx()
+	// Close all players connection
+	for _, conns := range []map[string]*PlayerConn{r.players, r.spectators} {
+		for name, p := range conns {
+			p.active = false
+			p.drop(code, reason)
+			delete(conns, name)
+		}
+	}
+	return true
 }
 
 // run processes all messages sent to the room.
-// This function is blocking until the room is closed (r.broadcast is closed)
+// This function is blocking until the room is closed, the room's context is cancelled when it returns
+// so that no message can be sent to the room anymore.
 func (r *Room) run() {
-	for {
+	defer close(r.stopped)
+	defer r.cancelCtx()
+	defer close(r.events)
+	for !r.closed.Load() {
+		tick, timeUp := r.clock.channels()
 		select {
 		case <-r.ctx.Done():
 			return
+		case <-tick:
+			r.tick()
+		case <-timeUp:
+			r.timeout()
+		case <-r.lobby.expired():
+			r.autoStart()
+		case <-r.handoffExpired():
+			r.promote()
 		case message := <-r.broadcast:
 			log.Debug().
 				Str("game", r.g.ID.String()).
@@ -377,37 +1080,89 @@ func (r *Room) run() {
 			switch message.Type {
 			case SStart:
 				r.start(message)
+			case SReady:
+				r.ready(message)
 			case SMessage:
 				r.message(message)
 			case SPlay:
 				r.play(message)
+			case SSync:
+				r.sync(message)
+			case SLeaderboard:
+				r.leaderboard(message)
 			case PJoin:
 				r.join(message)
-			case PLeave:
+			case PLeave, PKickout:
 				r.leave(message)
+			case PExpire:
+				r.expire(message)
+			case SKick:
+				r.moderate(message, ActionKick)
+			case SBan:
+				r.moderate(message, ActionBan)
+			case STransfer:
+				r.moderate(message, ActionTransfer)
+			case PQuery:
+				message.Data.(func())()
+			case PClose:
+				r.close()
+			case PRelease:
+				r.release()
 			default:
-				message.sender.write(newPayload(CError, "Unknown message type", withKey(message.Key)))
+				message.sender.fail(message.Key, ErrCodeUnknownEvent, "Unknown message type")
 			}
 		}
 	}
 }
 
-// tryBroadcast tries to broadcast the payload to all players in the room if the room is active.
-func (r *Room) tryBroadcast(payload Payload) {
+// tryBroadcast tries to broadcast the payload to all players in the room if the room is active,
+// it returns false when the room is closed.
+func (r *Room) tryBroadcast(payload Payload) bool {
 	select {
 	case <-r.ctx.Done():
+		return false
 	case r.broadcast <- payload:
+		return true
+	}
+}
+
+// storeEvents stores the events broadcast in the room until the event loop stops.
+func (r *Room) storeEvents() {
+	for payload := range r.events {
+		var err error
+		// ticks are not stored, the clock of a restored room is set from the deadline of its game
+		if payload.Type == CTick {
+			err = r.gs.SaveSeq(context.Background(), r.id, payload.Seq)
+		} else {
+			err = r.gs.AddEvent(context.Background(), r.id, payload)
+		}
+		if err != nil {
+			log.Err(err).Caller().Msg("failed to store event")
+		}
 	}
 }
 
-// sendAll sends the payload to all players in the room.
+// sendAll sends the payload to all players and spectators in the room, it is kept for the players who are reconnecting.
+// Every payload is given the next sequence number of the room and is added to its history.
 // If sending the payload fails, the player is removed from the room.
 func (r *Room) sendAll(payload Payload) {
+	r.seq++
+	payload.Seq = r.seq
+	r.history.add(payload)
+	// the event loop waits for the store when the queue is full, so that no event is lost
+	if r.gs != nil {
+		r.events <- payload
+	}
+	for _, a := range r.away {
+		a.miss(payload)
+	}
 	errs := make([]*PlayerConn, 0)
-	for _, p := range r.players {
-		err := p.write(payload)
-		if err != nil {
-			errs = append(errs, p)
+	for _, conns := range []map[string]*PlayerConn{r.players, r.spectators} {
+		for _, p := range conns {
+			err := p.write(payload)
+			if err != nil {
+				errs = append(errs, p)
+			}
 		}
 	}
 	if len(errs) != 0 {
@@ -420,20 +1175,69 @@ var (
 	pongWait = 10 * time.Second
 
 	pingInterval = (pongWait * 9) / 10
+
+	// writeWait is how long writing a message to a player can take before his connection is considered dead
+	writeWait = config.GetOrDefault("WRITE_TIMEOUT", 10*time.Second, time.ParseDuration)
+
+	// sendQueueSize is the number of messages waiting to be written to a player, players who let their queue fill up
+	// are dropped as slow consumers
+	sendQueueSize = config.GetOrDefault("SEND_QUEUE_SIZE", 512, strconv.Atoi)
+
+	// ownerGrace is how long the creator can be away from the room before another player becomes the creator
+	ownerGrace = 30 * time.Second
+
+	// startRetry is how long the room waits before starting its game again when the game failed to start automatically
+	startRetry = 5 * time.Second
+)
+
+var (
+	ErrSlowConsumer = errors.New("slow consumer: send queue is full")
+	ErrConnClosed   = errors.New("connection is closed")
 )
 
 // PlayerConn represents a player in the game.
 // A player can be in multiple rooms, but only one game at a time.
 type PlayerConn struct {
-	conn    *websocket.Conn
-	room    *Room
-	player  Player
-	writeMu sync.Mutex
-	active  bool // indicator for player's connection status
+	conn   *websocket.Conn
+	room   *Room
+	player Player
+	active bool // indicator for player's connection status
+	// send is the queue of encoded messages written to the player by his writer goroutine
+	send chan []byte
+	// done is closed when the connection is closed, the close code and reason are sent to the player
+	done        chan struct{}
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+	// spectator is true when the player watches the game without playing
+	spectator bool
+	// connectedAt is the time the connection was opened, the player connected for the longest time is promoted
+	// when the creator leaves the room
+	connectedAt time.Time
+	// token is the resume token sent to the player, resume is the token sent by the player to resume his session
+	token, resume string
+	// version is the version of the protocol negotiated with the player
+	version int
+	// codec encodes the messages exchanged with the player
+	codec Codec
+	// limiter limits the rate of the messages sent by the player
+	limiter *limiter
 
 	t *time.Ticker
 }
 
+// limiter returns the limiter of the messages sent by a player.
+func (r *Room) limiter(username string) *limiter {
+	r.limitersMu.Lock()
+	defer r.limitersMu.Unlock()
+	l, ok := r.limiters[username]
+	if !ok {
+		l = newLimiter(limits, maxViolations, time.Now())
+		r.limiters[username] = l
+	}
+	return l
+}
+
 // PName returns the player name.
 func (p *PlayerConn) PName() string {
 	return p.player.Username
@@ -441,9 +1245,9 @@ func (p *PlayerConn) PName() string {
 
 // newPlayerConn creates a new player.
 // This function starts the read goroutine to forward messages to the room.
-// Also starts the ping goroutine to ping the player every 5 seconds
+// Also starts the writer goroutine that writes the queued messages and pings the player every 5 seconds
 // to check if the player is still connected otherwise the connection is closed.
-func newPlayerConn(conn *websocket.Conn, room *Room, player Player) *PlayerConn {
+func newPlayerConn(conn *websocket.Conn, room *Room, player Player, spectator bool, opts ...ConnOption) *PlayerConn {
 	// Create a ticker to ping the player every 5 seconds
 	// The ticker is stored in the player struct so that it can be stopped
 	// on the player.Close() call.
@@ -453,64 +1257,171 @@ func newPlayerConn(conn *websocket.Conn, room *Room, player Player) *PlayerConn
 		conn:   conn,
 		room:   room,
 		active: true,
+		send:   make(chan []byte, sendQueueSize),
+		done:   make(chan struct{}),
+
+		spectator:   spectator,
+		connectedAt: time.Now(),
+		version:     ProtocolV1,
+		codec:       JSON,
+		limiter:     room.limiter(player.Username),
 
 		t: ticker,
 	}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	conn.SetReadLimit(maxMessageSize)
 	go p.read()
-	go p.ping()
+	go p.writer()
 	return &p
 }
 
-// Close closes the player connection.
+// Close closes the player connection, the messages already queued are written before the connection is closed.
 func (p *PlayerConn) close() error {
 	p.active = false
-	p.t.Stop()
-	return p.conn.Close()
+	p.drop(websocket.CloseNormalClosure, "")
+	return nil
 }
 
-// ping pings the player every 5 seconds to check if the player is still connected
-// otherwise the connection is closed.
-func (p *PlayerConn) ping() {
+// drop closes the player connection with the close `code` and `reason` sent to the player.
+// It can be called many times, only the first call closes the connection.
+func (p *PlayerConn) drop(code int, reason string) {
+	p.closeOnce.Do(func() {
+		p.closeCode, p.closeReason = code, reason
+		close(p.done)
+	})
+}
+
+// writer writes the queued messages to the player and pings him every 5 seconds to check if he is still connected,
+// every write has a deadline. The room is told that the player has left when a write fails.
+func (p *PlayerConn) writer() {
 	defer p.t.Stop()
-	for range p.t.C {
-		p.writeMu.Lock()
-		err := p.conn.WriteMessage(websocket.PingMessage, []byte{})
-		p.writeMu.Unlock()
-		if err != nil {
-			p.room.tryBroadcast(newPayload(PLeave, p))
+	defer p.conn.Close()
+	for {
+		select {
+		case message := <-p.send:
+			if err := p.writeMessage(p.codec.MessageType(), message); err != nil {
+				log.Err(err).Caller().Msgf("Error writing to player (%s)", p.PName())
+				p.room.tryBroadcast(newPayload(PLeave, p))
+				return
+			}
+		case <-p.t.C:
+			if err := p.writeMessage(websocket.PingMessage, []byte{}); err != nil {
+				p.room.tryBroadcast(newPayload(PLeave, p))
+				return
+			}
+		case <-p.done:
+			p.flush()
 			return
 		}
 	}
 }
 
+// flush writes the messages still queued when the connection is closed normally and sends the close message,
+// the queue of a slow consumer is not written.
+func (p *PlayerConn) flush() {
+	if p.closeCode == websocket.CloseNormalClosure {
+		for len(p.send) > 0 {
+			if err := p.writeMessage(p.codec.MessageType(), <-p.send); err != nil {
+				return
+			}
+		}
+	}
+	message := websocket.FormatCloseMessage(p.closeCode, p.closeReason)
+	_ = p.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeWait))
+}
+
+func (p *PlayerConn) writeMessage(messageType int, data []byte) error {
+	if err := p.conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return p.conn.WriteMessage(messageType, data)
+}
+
 // read reads messages from the player connection and forwards
 // them to the room to be processed.
+// Messages over the rate limit of their event are rejected, the player is kicked out when they keep sending them.
 func (p *PlayerConn) read() {
 	for {
-		var payload Payload
-		err := p.conn.ReadJSON(&payload)
+		_, message, err := p.conn.ReadMessage()
 		if err != nil {
 			p.room.tryBroadcast(newPayload(PLeave, p))
 			break
 		}
+		var request request
+		err = p.codec.Unmarshal(message, &request)
+		if wait := p.limiter.allow(request.Type, time.Now()); wait > 0 {
+			p.limit(request, wait)
+			continue
+		}
+		if err != nil {
+			p.fail("", ErrCodeInvalidMessage, "Invalid message")
+			continue
+		}
 		// if the payload type is not prefixed with "server/" then it is not allowed to be sent by the player.
-		if !strings.HasPrefix(string(payload.Type), "server/") {
-			p.write(newPayload(CError, "unsupported action", withKey(payload.Key)))
+		if !strings.HasPrefix(string(request.Type), "server/") {
+			p.fail(request.Key, ErrCodeUnsupportedAction, "unsupported action")
+			continue
+		}
+		data, err := decodeRequest(p.codec, request.Type, request.Data)
+		if err != nil {
+			p.fail(request.Key, ErrCodeInvalidMessage, "Invalid message type")
 			continue
 		}
+		payload := newPayload(request.Type, data, withKey(request.Key))
 		payload.From = p.PName() // From set by the client is ignored by the server for security reasons.
 		payload.sender = p
 		p.room.tryBroadcast(payload)
 	}
 }
 
-// write writes the payload to the player connection in synchronized manner.
+// limit rejects a request over its rate limit, the player can send it again after `wait`.
+func (p *PlayerConn) limit(request request, wait time.Duration) {
+	if p.limiter.offend(time.Now()) {
+		log.Warn().Caller().Msgf("Dropping player (%s), they keep sending too many messages", p.PName())
+		// the player is kicked out, so that they can not resume their session to get around the limits
+		p.drop(websocket.ClosePolicyViolation, ErrRateLimited.Error())
+		p.room.tryBroadcast(newPayload(PKickout, p))
+		return
+	}
+	retryAfter := int(wait.Round(time.Millisecond).Milliseconds())
+	p.write(newPayload(CError, ErrorResponse{
+		Code:       ErrCodeRateLimited,
+		Message:    fmt.Sprintf("Too many messages, retry in %dms", retryAfter),
+		RetryAfter: &retryAfter,
+	}, withKey(request.Key)))
+}
+
+// fail sends a `CError` event to the player with the code of the error.
+func (p *PlayerConn) fail(key string, code ErrorCode, message string) error {
+	return p.write(newPayload(CError, ErrorResponse{Code: code, Message: message}, withKey(key)))
+}
+
+// write queues the payload to be written to the player connection, it never blocks.
+// Errors and guesses are sent in the format of the version of the protocol used by the player and payloads are encoded with the
+// codec negotiated with the player.
+// The player is dropped when his queue is full, ErrSlowConsumer is returned.
 func (p *PlayerConn) write(payload Payload) error {
-	p.writeMu.Lock()
-	defer p.writeMu.Unlock()
-	err := p.conn.WriteJSON(payload)
+	if v, ok := payload.Data.(versioned); ok {
+		payload.Data = v.forVersion(p.version)
+	}
+	message, err := p.codec.Marshal(payload)
 	if err != nil {
-		log.Err(err).Caller().Msgf("Error writing to player (%s)", p.PName())
+		log.Err(err).Caller().Msgf("Error encoding payload (%s) for player (%s)", payload.Type, p.PName())
+		return err
+	}
+	select {
+	case <-p.done:
+		return ErrConnClosed
+	default:
+	}
+	select {
+	case p.send <- message:
+		return nil
+	default:
+		log.Warn().Caller().Msgf("Dropping slow player (%s), his send queue is full", p.PName())
+		p.drop(websocket.CloseTryAgainLater, ErrSlowConsumer.Error())
+		return ErrSlowConsumer
 	}
-	return err
 }
@@ -0,0 +1,1183 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+// fakeService records the games stored by a room.
+type fakeService struct {
+	finished  chan *Game
+	wiped     chan uuid.UUID
+	rounds    chan Round
+	moderated chan Moderation
+	owners    chan string
+	lobbies   chan []string
+	// matches receives the matches saved between rounds with the game of their next round
+	matches chan savedMatch
+	// events receives the events stored by the room
+	events chan Payload
+	// dropped receives the lobbies dropped by closed rooms, the lobbies are only recorded by the tests that read them
+	dropped chan uuid.UUID
+
+	mu      sync.Mutex
+	records []Record
+	// failRecords makes the records fail to be appended
+	failRecords atomic.Bool
+	// failStart makes the games fail to be started
+	failStart atomic.Bool
+}
+
+func newFakeService() *fakeService {
+	return &fakeService{
+		finished:  make(chan *Game, 1),
+		wiped:     make(chan uuid.UUID, 1),
+		rounds:    make(chan Round, 1),
+		moderated: make(chan Moderation, 1),
+		owners:    make(chan string, 1),
+		lobbies:   make(chan []string, 1),
+		dropped:   make(chan uuid.UUID, 1),
+		matches:   make(chan savedMatch, 1),
+	}
+}
+
+func (s *fakeService) FinishGame(_ context.Context, g *Game) error {
+	s.finished <- g
+	return nil
+}
+
+func (s *fakeService) StartGame(context.Context, *Game) error {
+	if s.failStart.Load() {
+		return errors.New("database is unavailable")
+	}
+	return nil
+}
+
+func (s *fakeService) WipeGameData(_ context.Context, id uuid.UUID) error {
+	s.wiped <- id
+	return nil
+}
+
+func (s *fakeService) DropLobby(_ context.Context, id uuid.UUID) error {
+	select {
+	case s.dropped <- id:
+	default:
+	}
+	return nil
+}
+
+func (s *fakeService) ValidateWord(string) bool { return true }
+
+func (s *fakeService) AddGuess(context.Context, uuid.UUID, string, word.Word, float64, bool) error {
+	return nil
+}
+
+func (s *fakeService) GenerateWord(int) string { return "WORLD" }
+
+func (s *fakeService) FinishRound(_ context.Context, m *Match, _ *Game) error {
+	s.rounds <- m.Played[len(m.Played)-1]
+	return nil
+}
+
+// savedMatch is a copy of a match saved between rounds.
+type savedMatch struct {
+	match *Match
+	next  *Game
+}
+
+func (s *fakeService) SaveMatch(_ context.Context, m *Match, next *Game) error {
+	cp := *m
+	cp.Played = slices.Clone(m.Played)
+	cp.Scores = maps.Clone(m.Scores)
+	select {
+	case s.matches <- savedMatch{match: &cp, next: next.Clone()}:
+	default:
+	}
+	return nil
+}
+
+func (s *fakeService) Moderate(_ context.Context, m Moderation) error {
+	s.moderated <- m
+	return nil
+}
+
+// AddEvent waits for the events to be read by the tests that set the events channel.
+func (s *fakeService) AddEvent(_ context.Context, _ uuid.UUID, p Payload) error {
+	if s.events != nil {
+		s.events <- p
+	}
+	return nil
+}
+
+// SaveSeq sends the sequence numbers of the events that are not stored as ticks to the tests that set the events channel.
+func (s *fakeService) SaveSeq(_ context.Context, _ uuid.UUID, seq uint64) error {
+	if s.events != nil {
+		s.events <- Payload{Type: CTick, Seq: seq}
+	}
+	return nil
+}
+
+func (s *fakeService) ChangeOwner(_ context.Context, g *Game) error {
+	s.owners <- g.Creator
+	return nil
+}
+
+func (s *fakeService) AppendRecord(_ context.Context, _ uuid.UUID, rec Record) error {
+	if s.failRecords.Load() {
+		return errors.New("log is unavailable")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+	return nil
+}
+
+// SaveLobby records the players of the lobby, the lobby is only recorded by the tests that read it.
+func (s *fakeService) SaveLobby(_ context.Context, g *Game) error {
+	players := g.Players()
+	slices.Sort(players)
+	select {
+	case s.lobbies <- players:
+	default:
+	}
+	return nil
+}
+
+func TestRoom_Timeout(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.TimeLimit = 60
+	g.Join(Player{Username: "fela"})
+	g.Start()
+	*g.StartedAt = time.Now().Add(-2 * time.Minute)
+
+	srv := newFakeService()
+	room := NewRoom(g, srv)
+
+	select {
+	case finished := <-srv.finished:
+		require.True(t, finished.HasEnded())
+		assert.True(t, finished.Sessions["fela"].Failed())
+	case <-srv.wiped:
+		t.Fatal("game data should be stored, not wiped, when the time is up")
+	case <-time.After(time.Second):
+		t.Fatal("game did not end at its deadline")
+	}
+	assert.True(t, room.IsClosed())
+}
+
+func TestRoom_RestoreEnded(t *testing.T) {
+	ended := func(rounds int) *Game {
+		g := New("fela", word.New("GAMES"))
+		g.Settings.Rounds = rounds
+		g.Join(Player{Username: "fela"})
+		g.Start()
+		g.Expire()
+		return g
+	}
+
+	t.Run("game", func(t *testing.T) {
+		srv := newFakeService()
+		room := RestoreRoom(ended(1), nil, 0, srv)
+		select {
+		case finished := <-srv.finished:
+			assert.True(t, finished.HasEnded())
+		case <-time.After(time.Second):
+			t.Fatal("the ended game was not stored")
+		}
+		assert.True(t, room.IsClosed())
+	})
+
+	t.Run("round", func(t *testing.T) {
+		srv := newFakeService()
+		g := ended(2)
+		room := RestoreRoom(g, nil, 0, srv)
+		t.Cleanup(room.Close)
+		select {
+		case round := <-srv.rounds:
+			assert.Equal(t, g.ID, round.GameID)
+		case <-time.After(time.Second):
+			t.Fatal("the ended round was not stored")
+		}
+		assert.False(t, room.IsClosed(), "the next round is played")
+		assert.NotEqual(t, g.ID, room.Game().ID)
+	})
+}
+
+func TestRoom_Match(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.TimeLimit = 60
+	g.Settings.Rounds = 2
+	g.Join(Player{Username: "fela"})
+	g.Start()
+	*g.StartedAt = time.Now().Add(-2 * time.Minute)
+
+	srv := newFakeService()
+	room := NewRoom(g, srv)
+
+	select {
+	case round := <-srv.rounds:
+		assert.Equal(t, 1, round.Number)
+		assert.Equal(t, g.ID, round.GameID)
+	case <-srv.finished:
+		t.Fatal("rounds of a match should be stored as rounds")
+	case <-time.After(time.Second):
+		t.Fatal("round did not end at its deadline")
+	}
+	assert.False(t, room.IsClosed(), "the room stays open until all the rounds are played")
+	assert.Equal(t, g.ID.String(), room.ID(), "the room keeps the id of its first game")
+	assert.NoError(t, room.CanJoin("fela"))
+	assert.Error(t, room.CanJoin("james"), "only members can join a match that has started")
+
+	// the room is restored between rounds with the scores of the match
+	saved := <-srv.matches
+	assert.NotEqual(t, g.ID, saved.next.ID, "the next round is a new game")
+	room.Close()
+	restored := RestoreMatch(saved.match, saved.next, nil, 0, srv)
+	t.Cleanup(restored.Close)
+	assert.Equal(t, g.ID.String(), restored.ID())
+	assert.Equal(t, saved.next.ID, restored.Game().ID)
+	assert.NoError(t, restored.CanJoin("fela"))
+	assert.Error(t, restored.CanJoin("james"), "the rounds played before the restore are kept")
+}
+
+// withoutLimits lifts the limits of the messages sent by players until the end of the test.
+func withoutLimits(t *testing.T) {
+	oldLimits, oldSize := limits, maxMessageSize
+	t.Cleanup(func() { limits, maxMessageSize = oldLimits, oldSize })
+	limits = map[Event]Limit{SMessage: {}, SPlay: {}}
+	maxMessageSize = 1 << 20
+}
+
+// connect opens a websocket connection to the room, the player joins the room as `role`.
+func connect(t *testing.T, room *Room, p Player, role Role, opts ...ConnOption) *websocket.Conn {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		if role == RoleSpectator {
+			room.Spectate(p, conn, opts...)
+			return
+		}
+		room.Join(p, conn, opts...)
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// expect reads messages from the connection until a message of the given event is received.
+func expect(t *testing.T, conn *websocket.Conn, event Event) map[string]any {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		var payload struct {
+			Type Event           `json:"event"`
+			Data json.RawMessage `json:"data"`
+		}
+		require.NoError(t, conn.ReadJSON(&payload), "did not receive %s", event)
+		if payload.Type != event {
+			continue
+		}
+		var data map[string]any
+		_ = json.Unmarshal(payload.Data, &data)
+		return data
+	}
+}
+
+// expectText reads messages from the connection until a message of the given event is received and returns its text.
+func expectText(t *testing.T, conn *websocket.Conn, event Event) string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		var payload Payload
+		require.NoError(t, conn.ReadJSON(&payload), "did not receive %s", event)
+		if payload.Type == event {
+			text, _ := payload.Data.(string)
+			return text
+		}
+	}
+}
+
+func TestRoom_Spectate(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	room := NewRoom(g, newFakeService())
+	t.Cleanup(room.Close)
+
+	player := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	expect(t, player, CData)
+
+	spectator := connect(t, room, Player{Username: "james"}, RoleSpectator)
+	data := expect(t, spectator, CData)
+	assert.EqualValues(t, 1, data["spectators"])
+	assert.EqualValues(t, map[string]any{"count": 1.0}, expect(t, player, CSpectators))
+
+	require.NoError(t, player.WriteJSON(Payload{Type: SStart}))
+	expect(t, spectator, CStart)
+
+	require.NoError(t, spectator.WriteJSON(Payload{Type: SPlay, Data: "GAMES"}))
+	expect(t, spectator, CError)
+
+	require.NoError(t, spectator.WriteJSON(Payload{Type: SMessage, Data: "good luck"}))
+	expect(t, player, CMessage)
+
+	require.NoError(t, player.WriteJSON(Payload{Type: SPlay, Data: "JAMES"}))
+	data = expect(t, spectator, CPlay)
+	assert.Len(t, data["leaderboard"], 1, "spectators are not ranked")
+	assert.NotContains(t, data["result"], "word", "spectators do not see the words played")
+
+	require.NoError(t, spectator.Close())
+	assert.EqualValues(t, map[string]any{"count": 0.0}, expect(t, player, CSpectators))
+}
+
+func TestRoom_Ready(t *testing.T) {
+	newRoom := func(settings func(*GameSettings)) (*Room, *websocket.Conn, *websocket.Conn) {
+		g := New("fela", word.New("GAMES"))
+		settings(&g.Settings)
+		room := NewRoom(g, newFakeService())
+		t.Cleanup(room.Close)
+
+		creator := connect(t, room, Player{Username: "fela"}, RolePlayer)
+		expect(t, creator, CData)
+		player := connect(t, room, Player{Username: "james"}, RolePlayer)
+		data := expect(t, player, CData)
+		assert.Equal(t, map[string]any{"players": []any{
+			map[string]any{"username": "fela", "ready": false},
+			map[string]any{"username": "james", "ready": false},
+		}}, data["lobby"], "players joining see the lobby")
+		return room, creator, player
+	}
+
+	t.Run("creator waits for players", func(t *testing.T) {
+		_, creator, player := newRoom(func(*GameSettings) {})
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: SStart}))
+		expect(t, creator, CError)
+
+		require.NoError(t, player.WriteJSON(Payload{Type: SReady}))
+		lobby := expect(t, creator, CLobby)
+		assert.Equal(t, []any{
+			map[string]any{"username": "fela", "ready": true},
+			map[string]any{"username": "james", "ready": true},
+		}, lobby["players"])
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: SStart}))
+		expect(t, player, CStart)
+	})
+
+	t.Run("auto start", func(t *testing.T) {
+		_, creator, player := newRoom(func(s *GameSettings) { s.AutoStart = true })
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: SReady, Data: true}))
+		expect(t, player, CLobby)
+		require.NoError(t, player.WriteJSON(Payload{Type: SReady, Data: true}))
+		expect(t, creator, CStart)
+	})
+
+	t.Run("countdown", func(t *testing.T) {
+		_, creator, player := newRoom(func(s *GameSettings) { s.Countdown = 1 })
+
+		require.NoError(t, player.WriteJSON(Payload{Type: SReady}))
+		lobby := expect(t, player, CLobby)
+		for lobby["countdown"] == nil { // skip the lobby events sent when players joined
+			lobby = expect(t, player, CLobby)
+		}
+		assert.EqualValues(t, 1, lobby["countdown"])
+		expect(t, creator, CStart)
+	})
+
+	t.Run("countdown restarts when the game fails to start", func(t *testing.T) {
+		retry := startRetry
+		startRetry = 50 * time.Millisecond
+		t.Cleanup(func() { startRetry = retry })
+		room, creator, player := newRoom(func(s *GameSettings) { s.Countdown = 1 })
+		srv := room.gs.(*fakeService)
+		srv.failStart.Store(true)
+
+		require.NoError(t, player.WriteJSON(Payload{Type: SReady}))
+		assert.Equal(t, "Failed to start game", expectText(t, creator, CError))
+		lobby := expect(t, creator, CLobby)
+		assert.NotNil(t, lobby["countdown"], "the countdown starts again")
+		assert.Nil(t, room.Game().StartedAt)
+
+		srv.failStart.Store(false)
+		expect(t, creator, CStart)
+	})
+}
+
+func TestRoom_Moderate(t *testing.T) {
+	newRoom := func() (*Room, *fakeService, *websocket.Conn, *websocket.Conn) {
+		g := New("fela", word.New("GAMES"))
+		srv := newFakeService()
+		room := NewRoom(g, srv)
+		t.Cleanup(room.Close)
+
+		creator := connect(t, room, Player{Username: "fela"}, RolePlayer)
+		expect(t, creator, CData)
+		player := connect(t, room, Player{Username: "james"}, RolePlayer)
+		expect(t, player, CData)
+		return room, srv, creator, player
+	}
+	moderated := func(t *testing.T, srv *fakeService) Moderation {
+		t.Helper()
+		select {
+		case m := <-srv.moderated:
+			return m
+		case <-time.After(time.Second):
+			t.Fatal("moderation was not stored")
+			return Moderation{}
+		}
+	}
+
+	t.Run("only the creator moderates", func(t *testing.T) {
+		_, _, creator, player := newRoom()
+
+		require.NoError(t, player.WriteJSON(Payload{Type: SKick, Data: "fela"}))
+		expect(t, player, CError)
+		require.NoError(t, creator.WriteJSON(Payload{Type: SKick, Data: "fela"}))
+		expect(t, creator, CError)
+		require.NoError(t, creator.WriteJSON(Payload{Type: SBan, Data: "unknown"}))
+		expect(t, creator, CError)
+	})
+
+	t.Run("kick", func(t *testing.T) {
+		room, srv, creator, player := newRoom()
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: SKick, Data: "james"}))
+		assert.Equal(t, map[string]any{"action": "kick", "moderator": "fela", "target": "james"}, expect(t, player, CModeration))
+		expect(t, creator, CLeave)
+
+		m := moderated(t, srv)
+		assert.Equal(t, ActionKick, m.Action)
+		assert.Equal(t, "james", m.Target)
+		assert.NotContains(t, room.Game().Sessions, "james", "the session is removed before the game starts")
+		assert.NoError(t, room.CanJoin("james"), "kicked players can join again")
+	})
+
+	t.Run("ban", func(t *testing.T) {
+		room, srv, creator, player := newRoom()
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: SBan, Data: "james"}))
+		expect(t, player, CModeration)
+
+		assert.Equal(t, ActionBan, moderated(t, srv).Action)
+		assert.NotContains(t, room.Game().Sessions, "james")
+		assert.Error(t, room.CanJoin("james"), "banned players can not join again")
+		assert.Error(t, room.CanSpectate("james"), "banned players can not watch the game")
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		room, srv, creator, player := newRoom()
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: STransfer, Data: "james"}))
+		expect(t, creator, CModeration)
+
+		assert.Equal(t, ActionTransfer, moderated(t, srv).Action)
+		assert.Equal(t, "james", <-srv.owners)
+		assert.Equal(t, "james", room.Game().Creator)
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: SKick, Data: "james"}))
+		expect(t, creator, CError)
+		require.NoError(t, player.WriteJSON(Payload{Type: SReady}))
+		require.NoError(t, creator.WriteJSON(Payload{Type: SReady}))
+		require.NoError(t, player.WriteJSON(Payload{Type: SStart}))
+		expect(t, creator, CStart)
+	})
+}
+
+func TestRoom_Handoff(t *testing.T) {
+	grace, reconnect := ownerGrace, reconnectGrace
+	ownerGrace, reconnectGrace = 100*time.Millisecond, 50*time.Millisecond
+	t.Cleanup(func() { ownerGrace, reconnectGrace = grace, reconnect })
+
+	owner := func(t *testing.T, srv *fakeService) string {
+		t.Helper()
+		select {
+		case creator := <-srv.owners:
+			return creator
+		case <-time.After(time.Second):
+			t.Fatal("the new creator was not stored")
+			return ""
+		}
+	}
+
+	t.Run("longest connected player is promoted", func(t *testing.T) {
+		srv := newFakeService()
+		room := NewRoom(New("fela", word.New("GAMES")), srv)
+		t.Cleanup(room.Close)
+
+		creator := connect(t, room, Player{Username: "fela"}, RolePlayer)
+		expect(t, creator, CData)
+		james := connect(t, room, Player{Username: "james"}, RolePlayer)
+		expect(t, james, CData)
+		alice := connect(t, room, Player{Username: "alice"}, RolePlayer)
+		expect(t, alice, CData)
+
+		require.NoError(t, creator.Close())
+		assert.Equal(t, map[string]any{"creator": "james"}, expect(t, alice, COwner))
+		assert.Equal(t, "james", owner(t, srv))
+		assert.Equal(t, "james", room.Game().Creator)
+
+		require.NoError(t, alice.WriteJSON(Payload{Type: SReady}))
+		lobby := expect(t, james, CLobby)
+		aliceReady := func(p any) bool {
+			return p.(map[string]any)["username"] == "alice" && p.(map[string]any)["ready"] == true
+		}
+		for !slices.ContainsFunc(lobby["players"].([]any), aliceReady) {
+			lobby = expect(t, james, CLobby) // skip the lobby events sent when players joined and left
+		}
+		require.NoError(t, james.WriteJSON(Payload{Type: SStart}))
+		expect(t, alice, CStart)
+	})
+
+	t.Run("next player to join is promoted", func(t *testing.T) {
+		srv := newFakeService()
+		room := NewRoom(New("fela", word.New("GAMES")), srv)
+		t.Cleanup(room.Close)
+
+		creator := connect(t, room, Player{Username: "fela"}, RolePlayer)
+		expect(t, creator, CData)
+		require.NoError(t, creator.Close())
+		time.Sleep(3 * (reconnectGrace + ownerGrace))
+
+		james := connect(t, room, Player{Username: "james"}, RolePlayer)
+		assert.Equal(t, map[string]any{"creator": "james"}, expect(t, james, COwner))
+		assert.Equal(t, "james", owner(t, srv))
+	})
+}
+
+// resume opens a new websocket connection to the room for a player who resumes his session with `token`.
+func resume(t *testing.T, room *Room, p Player, token string) *websocket.Conn {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		room.Resume(p, conn, token)
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestRoom_Reconnect(t *testing.T) {
+	grace := reconnectGrace
+	reconnectGrace = 200 * time.Millisecond
+	t.Cleanup(func() { reconnectGrace = grace })
+
+	newRoom := func() (*Room, *websocket.Conn, string) {
+		room := NewRoom(New("fela", word.New("GAMES")), newFakeService())
+		t.Cleanup(room.Close)
+
+		creator := connect(t, room, Player{Username: "fela"}, RolePlayer)
+		expect(t, creator, CData)
+		player := connect(t, room, Player{Username: "james"}, RolePlayer)
+		data := expect(t, player, CData)
+		require.NotEmpty(t, data["resume_token"])
+
+		require.NoError(t, player.Close())
+		assert.Equal(t, "james is reconnecting", expectText(t, creator, CReconnecting))
+		return room, creator, data["resume_token"].(string)
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		room, creator, token := newRoom()
+		require.NoError(t, creator.WriteJSON(Payload{Type: SMessage, Data: "are you there?"}))
+		expect(t, creator, CMessage)
+
+		player := resume(t, room, Player{Username: "james"}, token)
+		data := expect(t, player, CData)
+		assert.NotEqual(t, token, data["resume_token"], "a new token is issued for every connection")
+		assert.Equal(t, "are you there?", expectText(t, player, CMessage), "missed messages are replayed")
+		assert.Equal(t, "james has reconnected", expectText(t, creator, CJoin))
+
+		time.Sleep(2 * reconnectGrace)
+		require.NoError(t, creator.WriteJSON(Payload{Type: SMessage, Data: "welcome back"}))
+		assert.Equal(t, "welcome back", expectText(t, creator, CMessage), "the player does not leave when the window expires")
+		assert.Contains(t, room.Game().Sessions, "james")
+	})
+
+	t.Run("window expires", func(t *testing.T) {
+		room, creator, token := newRoom()
+		assert.Equal(t, "james has left", expectText(t, creator, CLeave))
+
+		player := resume(t, room, Player{Username: "james"}, token)
+		expect(t, player, CData)
+		assert.Equal(t, "james has joined", expectText(t, creator, CJoin), "the session can not be resumed after the window")
+	})
+}
+
+func TestRoom_Sync(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	events := []Payload{
+		{Type: CMessage, Data: "first", From: "james", Seq: 41},
+		{Type: CMessage, Data: "second", From: "james", Seq: 42},
+	}
+	// ticks were broadcast after the last stored event
+	room := RestoreRoom(g, events, 45, newFakeService())
+	t.Cleanup(room.Close)
+
+	player := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	data := expect(t, player, CData)
+	assert.EqualValues(t, 45, data["seq"], "the sequence continues from the last sequence number handed out")
+
+	var join Payload
+	require.NoError(t, player.ReadJSON(&join))
+	assert.Equal(t, CJoin, join.Type)
+	assert.EqualValues(t, 46, join.Seq)
+
+	require.NoError(t, player.WriteJSON(Payload{Type: SSync, Data: 41}))
+	assert.Equal(t, "second", expectText(t, player, CMessage))
+	assert.Equal(t, "fela has joined", expectText(t, player, CJoin))
+
+	require.NoError(t, player.WriteJSON(Payload{Type: SSync, Data: 10, Key: "resync"}))
+	data = expect(t, player, CData)
+	assert.EqualValues(t, 47, data["seq"], "the state is sent when the missed events are not kept anymore")
+}
+
+func TestRoom_ErrorCodes(t *testing.T) {
+	room := NewRoom(New("fela", word.New("GAMES")), newFakeService())
+	t.Cleanup(room.Close)
+
+	legacy := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	assert.EqualValues(t, ProtocolV1, expect(t, legacy, CData)["version"])
+	player := connect(t, room, Player{Username: "james"}, RolePlayer, WithVersion(ProtocolV2))
+	assert.EqualValues(t, ProtocolV2, expect(t, player, CData)["version"])
+
+	require.NoError(t, player.WriteJSON(Payload{Type: SPlay, Data: "GAMES", Key: "k1"}))
+	assert.Equal(t, map[string]any{"code": "not_active", "message": "Room isn't active"}, expect(t, player, CError))
+	require.NoError(t, player.WriteJSON(Payload{Type: SPlay, Data: 42}))
+	assert.Equal(t, "invalid_message", expect(t, player, CError)["code"])
+	require.NoError(t, player.WriteJSON(Payload{Type: "client/play"}))
+	assert.Equal(t, "unsupported_action", expect(t, player, CError)["code"])
+
+	require.NoError(t, legacy.WriteJSON(Payload{Type: SPlay, Data: "GAMES"}))
+	assert.Equal(t, "Room isn't active", expectText(t, legacy, CError), "errors are messages in the first version")
+}
+
+func TestRoom_Codec(t *testing.T) {
+	room := NewRoom(New("fela", word.New("GAMES")), newFakeService())
+	t.Cleanup(room.Close)
+
+	conn := connect(t, room, Player{Username: "fela"}, RolePlayer, WithCodec(MessagePack))
+	// read reads binary messages until a message of the given event is received.
+	read := func(event Event) map[string]any {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		for {
+			mt, b, err := conn.ReadMessage()
+			require.NoError(t, err, "did not receive %s", event)
+			require.Equal(t, websocket.BinaryMessage, mt)
+			var payload map[string]any
+			require.NoError(t, MessagePack.Unmarshal(b, &payload))
+			if payload["event"] == string(event) {
+				return payload
+			}
+		}
+	}
+	data := read(CData)["data"].(map[string]any)
+	assert.Equal(t, "fela", data["creator"], "fields have the same names as in JSON")
+
+	b, err := MessagePack.Marshal(Payload{Type: SMessage, Data: "hello", Key: "k1"})
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, b))
+	msg := read(CMessage)
+	assert.Equal(t, "hello", msg["data"])
+	assert.Equal(t, "fela", msg["from"])
+
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte{0xc1}))
+	assert.Equal(t, "Invalid message", read(CError)["data"])
+}
+
+func TestRoom_Leaderboard(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	for _, username := range []string{"fela", "james", "ada"} {
+		g.Join(Player{Username: username})
+	}
+	g.Start()
+	room := NewRoom(g, newFakeService())
+	t.Cleanup(room.Close)
+
+	player := connect(t, room, Player{Username: "fela"}, RolePlayer, WithVersion(ProtocolV3))
+	assert.EqualValues(t, 0, expect(t, player, CData)["leaderboard_version"])
+	legacy := connect(t, room, Player{Username: "james"}, RolePlayer, WithVersion(ProtocolV2))
+	expect(t, legacy, CData)
+
+	require.NoError(t, player.WriteJSON(Payload{Type: SPlay, Data: "GAMES"}))
+	delta := expect(t, player, CPlay)
+	assert.EqualValues(t, 1, delta["version"])
+	entries := delta["leaderboard"].([]any)
+	require.Len(t, entries, int(delta["rank_offset"].(float64))+1, "only the entries between the old and new rank are sent")
+	assert.Equal(t, "fela", entries[0].(map[string]any)["username"])
+	assert.EqualValues(t, 0, entries[0].(map[string]any)["rank"])
+
+	full := expect(t, legacy, CPlay)
+	assert.EqualValues(t, 1, full["version"])
+	assert.Len(t, full["leaderboard"], 3, "older versions receive the whole leaderboard")
+
+	require.NoError(t, player.WriteJSON(Payload{Type: SLeaderboard, Key: "resync"}))
+	snapshot := expect(t, player, CLeaderboard)
+	assert.EqualValues(t, 1, snapshot["version"])
+	assert.Len(t, snapshot["leaderboard"], 3)
+}
+
+func TestRoom_SlowConsumer(t *testing.T) {
+	defer func(size int) { sendQueueSize = size }(sendQueueSize)
+	sendQueueSize = 4
+	withoutLimits(t)
+	defer func(grace time.Duration) { reconnectGrace = grace }(reconnectGrace)
+	reconnectGrace = time.Minute
+
+	room := NewRoom(New("fela", word.New("GAMES")), newFakeService())
+	t.Cleanup(room.Close)
+
+	player := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	expect(t, player, CData)
+	slow := connect(t, room, Player{Username: "james"}, RolePlayer)
+
+	// james never reads, his send queue fills up once the socket buffers are full while fela reads every message
+	text := strings.Repeat("A", 64*1024)
+	var dropped bool
+	for i := 0; i < 1000 && !dropped; i++ {
+		require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: text}))
+		require.NoError(t, player.SetReadDeadline(time.Now().Add(2*time.Second)))
+		for {
+			var payload Payload
+			require.NoError(t, player.ReadJSON(&payload))
+			if payload.Type == CReconnecting {
+				assert.Equal(t, "james is reconnecting", payload.Data)
+				dropped = true
+			}
+			if payload.Type == CMessage {
+				break
+			}
+		}
+	}
+	require.True(t, dropped, "the slow player is dropped")
+
+	require.NoError(t, slow.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		_, _, err := slow.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		assert.Equal(t, websocket.CloseTryAgainLater, closeErr.Code)
+		assert.Equal(t, ErrSlowConsumer.Error(), closeErr.Text)
+		break
+	}
+}
+
+func TestRoom_Load(t *testing.T) {
+	if testing.Short() {
+		t.Skip("load test")
+	}
+	const (
+		spectators = 300
+		messages   = 50
+	)
+	withoutLimits(t)
+	room := NewRoom(New("fela", word.New("GAMES")), newFakeService())
+	t.Cleanup(room.Close)
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		room.Spectate(Player{Username: r.URL.Query().Get("username")}, conn)
+	}))
+	t.Cleanup(srv.Close)
+
+	player := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	expect(t, player, CData)
+
+	// every spectator counts the chat messages it receives
+	received := make(chan int, spectators)
+	for i := range spectators {
+		url := fmt.Sprintf("ws%s?username=spectator%d", strings.TrimPrefix(srv.URL, "http"), i)
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		go func() {
+			var count int
+			defer func() { received <- count }()
+			_ = conn.SetReadDeadline(time.Now().Add(20 * time.Second))
+			for count < messages {
+				var payload Payload
+				if err := conn.ReadJSON(&payload); err != nil {
+					return
+				}
+				if payload.Type == CMessage {
+					count++
+				}
+			}
+		}()
+	}
+	require.Eventually(t, func() bool { return expect(t, player, CSpectators)["count"] == float64(spectators) },
+		10*time.Second, time.Millisecond, "every spectator joined")
+
+	start := time.Now()
+	for i := range messages {
+		require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: fmt.Sprintf("message %d", i)}))
+	}
+	for range spectators {
+		assert.Equal(t, messages, <-received)
+	}
+	t.Logf("%d messages broadcast to %d connections in %s", messages, spectators, time.Since(start))
+}
+
+func TestRoom_Concurrent(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.TimeLimit = 60
+	g.Join(Player{Username: "fela"})
+	srv := newFakeService()
+	room := NewRoom(g, srv)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		username := fmt.Sprintf("player%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			role := RolePlayer
+			if i%2 == 0 {
+				role = RoleSpectator
+			}
+			conn := connect(t, room, Player{Username: username}, role)
+			expect(t, conn, CData)
+			assert.NoError(t, room.CanJoin(username))
+			assert.NoError(t, room.CanSpectate(username))
+			assert.NotNil(t, room.Game())
+			assert.False(t, room.IsClosed())
+			if i%3 == 0 {
+				conn.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Eventually(t, func() bool { return len(room.Game().Sessions) == 11 }, time.Second, time.Millisecond,
+		"every player has a session")
+
+	// the room is closed once, however many times it is asked to
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			room.Close()
+			assert.True(t, room.IsClosed())
+			assert.Error(t, room.CanJoin("fela"))
+			assert.Error(t, room.CanSpectate("fela"))
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, room.Game().Sessions, 11, "the state can be read once the room is closed")
+
+	// connections opened once the room is closed are closed too
+	conn := connect(t, room, Player{Username: "late"}, RolePlayer)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+}
+
+func TestRoom_CloseOnce(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	g.Settings.TimeLimit = 60
+	g.Join(Player{Username: "fela"})
+	g.Start()
+	srv := newFakeService()
+	room := NewRoom(g, srv)
+	conn := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	expect(t, conn, CData)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			room.Close()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, g.ID, <-srv.wiped, "the abandoned game is wiped")
+	assert.Empty(t, srv.wiped, "the game is wiped once")
+}
+
+func TestRoom_CloseLobby(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	srv := newFakeService()
+	room := NewRoom(g, srv)
+	conn := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	expect(t, conn, CData)
+
+	room.Close()
+	assert.Equal(t, g.ID, <-srv.dropped, "the lobby of a closed room is dropped")
+	assert.Empty(t, srv.wiped, "lobbies are not stored in the database")
+}
+
+func TestRoom_RateLimit(t *testing.T) {
+	oldLimits, oldViolations := limits, maxViolations
+	t.Cleanup(func() { limits, maxViolations = oldLimits, oldViolations })
+	limits = map[Event]Limit{SMessage: {Rate: 0.1, Burst: 2}}
+	maxViolations = 2
+
+	room := NewRoom(New("fela", word.New("GAMES")), newFakeService())
+	t.Cleanup(room.Close)
+	observer := connect(t, room, Player{Username: "james"}, RolePlayer)
+	expect(t, observer, CData)
+	player := connect(t, room, Player{Username: "fela"}, RolePlayer, WithVersion(ProtocolV2))
+	token := expect(t, player, CData)["resume_token"].(string)
+
+	for range 2 {
+		require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: "hello"}))
+		assert.Equal(t, "hello", expectText(t, player, CMessage))
+	}
+	require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: "hello", Key: "k1"}))
+	e := expect(t, player, CError)
+	assert.Equal(t, "rate_limited", e["code"])
+	assert.InDelta(t, 10000, e["retry_after"], 100, "a token is added every 10 seconds")
+
+	// the player keeps sending messages over the limit
+	for range 2 {
+		require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: "hello"}))
+	}
+	require.NoError(t, player.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		_, _, err := player.ReadMessage()
+		if err == nil {
+			continue
+		}
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err)
+		break
+	}
+	assert.Equal(t, "fela has been kicked out", expectText(t, observer, CLeave))
+	assert.False(t, room.CanResume("fela", token), "the session can not be resumed after the player was dropped")
+
+	player = resume(t, room, Player{Username: "fela"}, token)
+	expect(t, player, CData)
+	assert.Equal(t, "fela has joined", expectText(t, observer, CJoin), "the player joins again instead of resuming")
+
+	// the limits of the player are kept when they connect again
+	require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: "hello"}))
+	require.NoError(t, player.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		_, _, err := player.ReadMessage()
+		if err == nil {
+			continue
+		}
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err)
+		break
+	}
+}
+
+func TestRoom_ReadLimit(t *testing.T) {
+	defer func(size int64) { maxMessageSize = size }(maxMessageSize)
+	maxMessageSize = 64
+
+	room := NewRoom(New("fela", word.New("GAMES")), newFakeService())
+	t.Cleanup(room.Close)
+	player := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	expect(t, player, CData)
+
+	require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: strings.Repeat("A", 128)}))
+	require.NoError(t, player.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		_, _, err := player.ReadMessage()
+		if err == nil {
+			continue
+		}
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), err)
+		break
+	}
+}
+
+func TestRoom_SaveLobby(t *testing.T) {
+	g := New("fela", word.New("GAMES"))
+	srv := newFakeService()
+	room := NewRoom(g, srv)
+	t.Cleanup(room.Close)
+	lobby := func(t *testing.T) []string {
+		t.Helper()
+		select {
+		case players := <-srv.lobbies:
+			return players
+		case <-time.After(time.Second):
+			t.Fatal("lobby was not stored")
+			return nil
+		}
+	}
+
+	creator := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	expect(t, creator, CData)
+	assert.Equal(t, []string{"fela"}, lobby(t))
+	player := connect(t, room, Player{Username: "james"}, RolePlayer)
+	expect(t, player, CData)
+	assert.Equal(t, []string{"fela", "james"}, lobby(t))
+
+	require.NoError(t, creator.WriteJSON(Payload{Type: SKick, Data: "james"}))
+	expect(t, creator, CLeave)
+	assert.Equal(t, []string{"fela"}, lobby(t), "the lobby is stored when a player leaves")
+}
+
+func TestRoom_Record(t *testing.T) {
+	newRoom := func(t *testing.T) (*Room, *fakeService, *websocket.Conn, *websocket.Conn) {
+		g := New("fela", word.New("GAMES"))
+		srv := newFakeService()
+		srv.AppendRecord(context.Background(), g.ID, CreateRecord(g))
+		room := NewRoom(g, srv)
+		t.Cleanup(room.Close)
+
+		creator := connect(t, room, Player{Username: "fela", ID: 1}, RolePlayer)
+		expect(t, creator, CData)
+		player := connect(t, room, Player{Username: "james", ID: 2, Password: "hash"}, RolePlayer)
+		expect(t, player, CData)
+		require.NoError(t, player.WriteJSON(Payload{Type: SReady}))
+		for ready := false; !ready; {
+			lobby := expect(t, creator, CLobby)
+			ready = slices.ContainsFunc(lobby["players"].([]any), func(p any) bool {
+				return p.(map[string]any)["username"] == "james" && p.(map[string]any)["ready"] == true
+			})
+		}
+		require.NoError(t, creator.WriteJSON(Payload{Type: SStart}))
+		expect(t, player, CStart)
+		return room, srv, creator, player
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		_, srv, creator, player := newRoom(t)
+		for _, guess := range []string{"JAMES", "GAMES"} {
+			require.NoError(t, player.WriteJSON(Payload{Type: SPlay, Data: guess}))
+			expect(t, player, CPlay)
+		}
+		require.NoError(t, creator.WriteJSON(Payload{Type: SPlay, Data: "GAMER"}))
+		// the creator receives the guesses of every player
+		for range 3 {
+			expect(t, creator, CPlay)
+		}
+
+		srv.mu.Lock()
+		records := slices.Clone(srv.records)
+		srv.mu.Unlock()
+		replayed, err := Replay(records)
+		require.NoError(t, err)
+		assert.Equal(t, Player{Username: "james", ID: 2}, replayed.Sessions["james"].Player, "passwords are not written to the log")
+		assert.Len(t, replayed.Sessions["james"].Guesses, 2)
+		assert.True(t, replayed.Sessions["james"].Won())
+		assert.Equal(t, map[string]int{"james": 0, "fela": 1}, replayed.Leaderboard.Positions)
+		assert.Equal(t, 3, replayed.LeaderboardVersion())
+
+		again, err := Replay(records)
+		require.NoError(t, err)
+		// comparators are functions, they are never equal
+		replayed.Leaderboard.cmp, again.Leaderboard.cmp = nil, nil
+		assert.Equal(t, replayed, again, "replay is deterministic")
+	})
+
+	t.Run("guesses are written before they are played", func(t *testing.T) {
+		room, srv, creator, _ := newRoom(t)
+		srv.failRecords.Store(true)
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: SPlay, Data: "JAMES"}))
+		assert.Equal(t, "Failed to store guess", expectText(t, creator, CError))
+		assert.Empty(t, room.Game().Sessions["fela"].Guesses)
+	})
+
+	t.Run("games are saved before they start", func(t *testing.T) {
+		g := New("fela", word.New("GAMES"))
+		srv := newFakeService()
+		srv.failStart.Store(true)
+		room := NewRoom(g, srv)
+		t.Cleanup(room.Close)
+		creator := connect(t, room, Player{Username: "fela", ID: 1}, RolePlayer)
+		expect(t, creator, CData)
+
+		require.NoError(t, creator.WriteJSON(Payload{Type: SStart}))
+		assert.Equal(t, "Failed to start game", expectText(t, creator, CError))
+		assert.Nil(t, room.Game().StartedAt, "the room stays in its lobby")
+		starts := func() int {
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			return len(slices.DeleteFunc(slices.Clone(srv.records), func(rec Record) bool { return rec.Kind != RecordStart }))
+		}
+		assert.Zero(t, starts(), "the start is not written to the log")
+
+		srv.failStart.Store(false)
+		require.NoError(t, creator.WriteJSON(Payload{Type: SStart}))
+		expect(t, creator, CStart)
+		assert.NotNil(t, room.Game().StartedAt)
+		assert.Equal(t, 1, starts())
+	})
+}
+
+func TestRoom_StoreEvents(t *testing.T) {
+	interval := tickInterval
+	tickInterval = 20 * time.Millisecond
+	t.Cleanup(func() { tickInterval = interval })
+	withoutLimits(t)
+
+	g := New("fela", word.New("GAMES"))
+	g.Settings.TimeLimit = 60
+	g.Join(Player{Username: "fela"})
+	g.Start()
+	srv := newFakeService()
+	srv.events = make(chan Payload)
+	room := NewRoom(g, srv)
+	t.Cleanup(room.Close)
+	t.Cleanup(func() {
+		// the store is released, so that the room can stop
+		go func() {
+			for range srv.events {
+			}
+		}()
+	})
+
+	player := connect(t, room, Player{Username: "fela"}, RolePlayer)
+	expect(t, player, CData)
+	expect(t, player, CTick)
+	require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: "hello"}))
+	assert.Equal(t, "hello", expectText(t, player, CMessage), "the room does not wait for the store")
+
+	seq := uint64(1)
+	for e := range srv.events {
+		assert.Equal(t, seq, e.Seq, "the sequence numbers of all events are stored")
+		seq++
+		if e.Type == CMessage {
+			break
+		}
+	}
+
+	// the room waits for the store when the queue is full, instead of losing the events
+	for i := range MaxHistory + 10 {
+		require.NoError(t, player.WriteJSON(Payload{Type: SMessage, Data: fmt.Sprint(i)}))
+	}
+	for e := range srv.events {
+		assert.Equal(t, seq, e.Seq, "no event is lost")
+		seq++
+		if e.Type == CMessage && e.Data == fmt.Sprint(MaxHistory+9) {
+			break
+		}
+	}
+}
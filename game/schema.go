@@ -0,0 +1,173 @@
+package game
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// enums contains the values of the types of the protocol that are enumerations
+var enums = map[reflect.Type][]any{
+	reflect.TypeFor[Action]():    {ActionKick, ActionBan, ActionTransfer},
+	reflect.TypeFor[Mode]():      {Classic, Sprint, Wizard},
+	reflect.TypeFor[ErrorCode](): toAny(ErrorCodes),
+}
+
+func toAny[T any](values []T) []any {
+	res := make([]any, len(values))
+	for i, v := range values {
+		res[i] = v
+	}
+	return res
+}
+
+// Schema returns the JSON Schema of the messages of the live socket generated from the types of their data.
+// Every event is described in `$defs`, requests are the events sent by clients and responses the events sent by the server.
+func Schema() map[string]any {
+	s := schemaBuilder{defs: make(map[string]any)}
+	var requestRefs, responseRefs []any
+	for _, event := range sortedEvents(requests) {
+		requestRefs = append(requestRefs, s.event(event, reflect.TypeOf(requests[event])))
+	}
+	for _, event := range sortedEvents(responses) {
+		responseRefs = append(responseRefs, s.event(event, responses[event]))
+	}
+	s.defs["Request"] = map[string]any{"oneOf": requestRefs}
+	s.defs["Response"] = map[string]any{"oneOf": responseRefs}
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     "https://github.com/kodekulture/wordle-server/protocol.schema.json",
+		"title":   "Wordle live protocol",
+		"description": "Messages of the live socket. Clients using version 1 of the protocol receive the message of an ErrorResponse, " +
+			"or its hint, as the data of client/error.",
+		"x-version": ProtocolVersion,
+		"anyOf":     []any{ref("Request"), ref("Response")},
+		"$defs":     s.defs,
+	}
+}
+
+func sortedEvents[V any](m map[Event]V) []Event {
+	events := make([]Event, 0, len(m))
+	for e := range m {
+		events = append(events, e)
+	}
+	slices.Sort(events)
+	return events
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/$defs/" + name}
+}
+
+type schemaBuilder struct {
+	defs map[string]any
+}
+
+// event defines the payload of an event with data of type t and returns a reference to it.
+func (s schemaBuilder) event(event Event, t reflect.Type) map[string]any {
+	// server/play is defined as ServerPlay
+	var name strings.Builder
+	for _, part := range strings.FieldsFunc(string(event), func(r rune) bool { return r == '/' || r == '_' }) {
+		name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	s.defs[name.String()] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"event": map[string]any{"const": event},
+			"data":  s.schema(t),
+			"from":  map[string]any{"type": "string"},
+			"key":   map[string]any{"type": "string"},
+			"seq":   map[string]any{"type": "integer", "minimum": 0},
+		},
+		"required": []string{"event"},
+	}
+	return ref(name.String())
+}
+
+// schema returns the schema of the type t, named types are defined in `$defs` and referenced.
+func (s schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeFor[uuid.UUID]():
+		return map[string]any{"type": "string", "format": "uuid"}
+	}
+	if t.Kind() == reflect.Pointer {
+		return s.schema(t.Elem())
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		return s.inline(t)
+	}
+	if _, ok := s.defs[t.Name()]; !ok {
+		s.defs[t.Name()] = nil // types referencing themselves are defined once
+		s.defs[t.Name()] = s.inline(t)
+	}
+	return ref(t.Name())
+}
+
+// inline returns the schema of the type t without referencing it.
+func (s schemaBuilder) inline(t reflect.Type) map[string]any {
+	res := make(map[string]any)
+	switch t.Kind() {
+	case reflect.String:
+		res["type"] = "string"
+	case reflect.Bool:
+		res["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		res["type"] = "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		res["type"] = "integer"
+		res["minimum"] = 0
+	case reflect.Float32, reflect.Float64:
+		res["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		res["type"] = "array"
+		res["items"] = s.schema(t.Elem())
+	case reflect.Map:
+		res["type"] = "object"
+		res["additionalProperties"] = s.schema(t.Elem())
+	case reflect.Struct:
+		properties, required := make(map[string]any), make([]string, 0)
+		s.fields(t, properties, &required)
+		res["type"] = "object"
+		res["properties"] = properties
+		if len(required) > 0 {
+			res["required"] = required
+		}
+	}
+	if values, ok := enums[t]; ok {
+		res["enum"] = values
+	}
+	return res
+}
+
+// fields adds the fields of the struct t encoded in JSON to properties, embedded structs are flattened.
+func (s schemaBuilder) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			s.fields(f.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		omitempty := strings.Contains(opts, "omitempty")
+		properties[name] = s.schema(f.Type)
+		if f.Type.Kind() == reflect.Pointer && !omitempty {
+			// nil pointers are encoded as null
+			properties[name] = map[string]any{"anyOf": []any{properties[name], map[string]any{"type": "null"}}}
+		}
+		if !omitempty {
+			*required = append(*required, name)
+		}
+	}
+}
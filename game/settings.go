@@ -0,0 +1,149 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+var (
+	ErrInvalidMode         = errors.New("invalid game mode")
+	ErrInvalidWordLength   = fmt.Errorf("word length must be between %d and %d", word.MinLength, word.MaxLength)
+	ErrInvalidMaxGuesses   = fmt.Errorf("max guesses must be between 1 and %d", MaxGuesses)
+	ErrInvalidTimeLimit    = fmt.Errorf("time limit must be between %v and %v", MinDuration, MaxDuration)
+	ErrInvalidLobbyTimeout = fmt.Errorf("lobby timeout must be between %v and %v", MinDuration, MaxLobbyDuration)
+	ErrInvalidRounds       = fmt.Errorf("rounds must be between 1 and %d", MaxRounds)
+	ErrInvalidCountdown    = fmt.Errorf("countdown must be between 0 and %v", MaxCountdown)
+)
+
+// Mode determines how players are allowed to play a game and how they are ranked.
+type Mode string
+
+const (
+	// Classic gives every player a limited number of attempts and ranks them by their best guess.
+	Classic Mode = "classic"
+	// Sprint gives every player unlimited attempts and ranks them by the time it took them to find the word.
+	Sprint Mode = "sprint"
+	// Wizard gives every player a limited number of attempts and ranks them by the number of attempts it took them to find the word.
+	Wizard Mode = "wizard"
+)
+
+// Unlimited is used as the number of allowed guesses when a session has no limit.
+const Unlimited = -1
+
+// GameSettings contains the rules chosen by the creator of a game.
+type GameSettings struct {
+	Mode       Mode `json:"mode"`
+	WordLength int  `json:"word_length"`
+	// MaxGuesses is the number of guesses each player is allowed to make, it is Unlimited in Sprint mode.
+	MaxGuesses int `json:"max_guesses"`
+	// TimeLimit is the number of seconds the game lasts once it has started.
+	TimeLimit int `json:"time_limit"`
+	// LobbyTimeout is the number of seconds the game waits for its creator to start it.
+	LobbyTimeout int `json:"lobby_timeout"`
+	// HardMode requires every guess to use the hints revealed by the previous guesses of the player.
+	HardMode bool `json:"hard_mode"`
+	// Rounds is the number of games of the match played in the room, the room plays a single game when it is 1.
+	Rounds int `json:"rounds"`
+	// AutoStart starts the game as soon as every player in the lobby is ready.
+	AutoStart bool `json:"auto_start"`
+	// Countdown is the number of seconds after which the game starts once a player is ready, it is disabled when 0.
+	Countdown int `json:"countdown"`
+}
+
+// DefaultSettings returns the settings used for a game when the creator does not choose any.
+func DefaultSettings() GameSettings {
+	return GameSettings{
+		Mode:         Classic,
+		WordLength:   word.DefaultLength,
+		MaxGuesses:   DefaultGuesses,
+		TimeLimit:    int(MaxDuration.Seconds()),
+		LobbyTimeout: int(MaxLobbyDuration.Seconds()),
+		Rounds:       1,
+	}
+}
+
+// Validate returns an error if the settings can not be used to create a game.
+func (s GameSettings) Validate() error {
+	switch s.Mode {
+	case Classic, Sprint, Wizard:
+	default:
+		return ErrInvalidMode
+	}
+	if !word.Supported(s.WordLength) {
+		return ErrInvalidWordLength
+	}
+	if s.MaxGuesses < 1 || s.MaxGuesses > MaxGuesses {
+		return ErrInvalidMaxGuesses
+	}
+	if s.Duration() < MinDuration || s.Duration() > MaxDuration {
+		return ErrInvalidTimeLimit
+	}
+	if s.LobbyDuration() < MinDuration || s.LobbyDuration() > MaxLobbyDuration {
+		return ErrInvalidLobbyTimeout
+	}
+	if s.Rounds < 1 || s.Rounds > MaxRounds {
+		return ErrInvalidRounds
+	}
+	if s.Countdown < 0 || s.CountdownDuration() > MaxCountdown {
+		return ErrInvalidCountdown
+	}
+	return nil
+}
+
+// Duration returns how long the game lasts once it has started.
+func (s GameSettings) Duration() time.Duration {
+	return time.Duration(s.TimeLimit) * time.Second
+}
+
+// LobbyDuration returns how long the game waits for its creator to start it.
+func (s GameSettings) LobbyDuration() time.Duration {
+	return time.Duration(s.LobbyTimeout) * time.Second
+}
+
+// CountdownDuration returns how long the game waits to be started once a player is ready.
+func (s GameSettings) CountdownDuration() time.Duration {
+	return time.Duration(s.Countdown) * time.Second
+}
+
+// withDefaults returns the settings with the rules of the mode applied and
+// the default value of every setting that is not set, like in games stored before the setting existed.
+func (s GameSettings) withDefaults() GameSettings {
+	def := DefaultSettings()
+	if s.Mode == "" {
+		s.Mode = def.Mode
+	}
+	if s.WordLength == 0 {
+		s.WordLength = def.WordLength
+	}
+	if s.MaxGuesses == 0 {
+		s.MaxGuesses = def.MaxGuesses
+	}
+	if s.TimeLimit == 0 {
+		s.TimeLimit = def.TimeLimit
+	}
+	if s.LobbyTimeout == 0 {
+		s.LobbyTimeout = def.LobbyTimeout
+	}
+	if s.Rounds == 0 {
+		s.Rounds = def.Rounds
+	}
+	if s.Mode == Sprint {
+		s.MaxGuesses = Unlimited
+	}
+	return s
+}
+
+// comparator returns the comparator used to rank sessions with these settings.
+func (s GameSettings) comparator() SessionComparator {
+	switch s.Mode {
+	case Sprint:
+		return BySprint
+	case Wizard:
+		return ByWizard
+	default:
+		return ByClassic
+	}
+}
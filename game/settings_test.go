@@ -0,0 +1,117 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+func TestGameSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings func(GameSettings) GameSettings
+		wantErr  error
+	}{
+		{
+			name:     "default settings",
+			settings: func(s GameSettings) GameSettings { return s },
+		},
+		{
+			name:     "unknown mode",
+			settings: func(s GameSettings) GameSettings { s.Mode = "unknown"; return s },
+			wantErr:  ErrInvalidMode,
+		},
+		{
+			name:     "shortest word",
+			settings: func(s GameSettings) GameSettings { s.WordLength = word.MinLength; return s },
+		},
+		{
+			name:     "longest word",
+			settings: func(s GameSettings) GameSettings { s.WordLength = word.MaxLength; return s },
+		},
+		{
+			name:     "word too short",
+			settings: func(s GameSettings) GameSettings { s.WordLength = word.MinLength - 1; return s },
+			wantErr:  ErrInvalidWordLength,
+		},
+		{
+			name:     "no guesses",
+			settings: func(s GameSettings) GameSettings { s.MaxGuesses = 0; return s },
+			wantErr:  ErrInvalidMaxGuesses,
+		},
+		{
+			name:     "too many guesses",
+			settings: func(s GameSettings) GameSettings { s.MaxGuesses = MaxGuesses + 1; return s },
+			wantErr:  ErrInvalidMaxGuesses,
+		},
+		{
+			name:     "time limit too long",
+			settings: func(s GameSettings) GameSettings { s.TimeLimit = int(MaxDuration.Seconds()) + 1; return s },
+			wantErr:  ErrInvalidTimeLimit,
+		},
+		{
+			name:     "lobby timeout too short",
+			settings: func(s GameSettings) GameSettings { s.LobbyTimeout = int(MinDuration.Seconds()) - 1; return s },
+			wantErr:  ErrInvalidLobbyTimeout,
+		},
+		{
+			name:     "word too long",
+			settings: func(s GameSettings) GameSettings { s.WordLength = word.MaxLength + 1; return s },
+			wantErr:  ErrInvalidWordLength,
+		},
+		{
+			name:     "match",
+			settings: func(s GameSettings) GameSettings { s.Rounds = MaxRounds; return s },
+		},
+		{
+			name:     "countdown too long",
+			settings: func(s GameSettings) GameSettings { s.Countdown = int(MaxCountdown.Seconds()) + 1; return s },
+			wantErr:  ErrInvalidCountdown,
+		},
+		{
+			name:     "no rounds",
+			settings: func(s GameSettings) GameSettings { s.Rounds = 0; return s },
+			wantErr:  ErrInvalidRounds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings(DefaultSettings()).Validate()
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestGame_WordLength(t *testing.T) {
+	g := New("test", word.New("GAMES"))
+	assert.Equal(t, word.DefaultLength, g.WordLength())
+
+	g.Settings.WordLength = 7
+	assert.Equal(t, 7, g.WordLength())
+
+	t.Log("games stored without a word length use the default length")
+	g.Settings = GameSettings{}
+	assert.Equal(t, word.DefaultLength, g.WordLength())
+}
+
+func TestGame_MaxGuesses(t *testing.T) {
+	g := New("test", word.New("GAMES"))
+	g.Settings.MaxGuesses = 2
+	g.Join(Player{Username: "test"})
+	g.Start()
+
+	for range 2 {
+		w := word.New("GAMAS")
+		_, _, err := g.Play("test", &w)
+		assert.NoError(t, err)
+	}
+	assert.False(t, g.Sessions["test"].CanPlay())
+	assert.True(t, g.Sessions["test"].Ended())
+	assert.True(t, g.HasEnded())
+
+	w := word.New("GAMES")
+	_, _, err := g.Play("test", &w)
+	assert.ErrorIs(t, err, ErrSessionEnded)
+}
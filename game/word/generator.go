@@ -4,5 +4,7 @@ package word
 
 type Generator interface {
 	Generate(length int) string
+	// Pick returns a word chosen by seed, the same word is returned every time the same seed is used
+	Pick(length int, seed uint64) string
 	Validate(guess string) bool
 }
@@ -3,46 +3,96 @@
 package word
 
 import (
-	_ "embed"
+	"embed"
 	"math/rand/v2"
 	"strconv"
 	"strings"
 )
 
+const (
+	// MinLength is the length of the shortest word that can be guessed
+	MinLength = 4
+	// MaxLength is the length of the longest word that can be guessed
+	MaxLength = 8
+	// DefaultLength is the length of the word to be guessed when none is chosen
+	DefaultLength = 5
+)
+
 var (
-	//go:embed resources/five_letter_words.txt
-	fileContent string
+	//go:embed resources/*_letter_words.txt resources/*_letter_guesses.txt
+	resources embed.FS
 
-	// Length is the length of the word to be guessed
-	Length = 5
+	// dictionaries contains the file with the words to guess of each supported length, they are valid guesses too
+	dictionaries = map[int]string{
+		4: "resources/four_letter_words.txt",
+		5: "resources/five_letter_words.txt",
+		6: "resources/six_letter_words.txt",
+		7: "resources/seven_letter_words.txt",
+		8: "resources/eight_letter_words.txt",
+	}
+	// guesses contains the file with the other valid guesses of a length, these words are never chosen as the word to guess
+	guesses = map[int]string{
+		4: "resources/four_letter_guesses.txt",
+	}
 )
 
-// localWordGenerator generates a word from one of the words in fileContent
+// Supported returns true if words of the given length can be generated
+func Supported(length int) bool {
+	_, ok := dictionaries[length]
+	return ok
+}
+
+// localWordGenerator generates a word from one of the words in the embedded dictionaries
 type localWordGenerator struct {
-	wordsArray []string
+	wordsArray map[int][]string
 	wordsMap   map[string]struct{}
 }
 
 func NewLocalGen() *localWordGenerator {
 	g := localWordGenerator{
-		wordsMap: make(map[string]struct{}),
+		wordsArray: make(map[int][]string),
+		wordsMap:   make(map[string]struct{}),
 	}
 	g.loadWords()
 	return &g
 }
 
 func (g *localWordGenerator) loadWords() {
-	g.wordsArray = strings.Split(fileContent, "\n")
-	for _, word := range g.wordsArray {
-		g.wordsMap[word] = struct{}{}
+	for length, file := range dictionaries {
+		content, err := resources.ReadFile(file)
+		if err != nil {
+			panic("missing dictionary for " + strconv.Itoa(length) + " letter words: " + err.Error())
+		}
+		g.wordsArray[length] = strings.Fields(string(content))
+		for _, word := range g.wordsArray[length] {
+			g.wordsMap[word] = struct{}{}
+		}
+	}
+	for length, file := range guesses {
+		content, err := resources.ReadFile(file)
+		if err != nil {
+			panic("missing guesses for " + strconv.Itoa(length) + " letter words: " + err.Error())
+		}
+		for _, word := range strings.Fields(string(content)) {
+			g.wordsMap[word] = struct{}{}
+		}
 	}
 }
 
 func (g *localWordGenerator) Generate(length int) string {
-	if length != Length {
-		panic("only " + strconv.Itoa(Length) + " letter words are supported")
+	words, ok := g.wordsArray[length]
+	if !ok {
+		panic("only " + strconv.Itoa(MinLength) + " to " + strconv.Itoa(MaxLength) + " letter words are supported")
+	}
+	return words[rand.IntN(len(words))]
+}
+
+func (g *localWordGenerator) Pick(length int, seed uint64) string {
+	words, ok := g.wordsArray[length]
+	if !ok {
+		panic("only " + strconv.Itoa(MinLength) + " to " + strconv.Itoa(MaxLength) + " letter words are supported")
 	}
-	return g.wordsArray[rand.IntN(len(g.wordsArray))]
+	return words[seed%uint64(len(words))]
 }
 
 func (g *localWordGenerator) Validate(guess string) bool {
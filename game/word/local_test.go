@@ -12,19 +12,27 @@ import (
 
 func TestNewLocalGen(t *testing.T) {
 	got := NewLocalGen()
-	assert.Greater(t, len(got.wordsArray), 0, "words should be loaded")
+	for length := MinLength; length <= MaxLength; length++ {
+		assert.Greater(t, len(got.wordsArray[length]), 0, "%d letter words should be loaded", length)
+	}
 }
 
 func Test_localWordGenerator_Generate(t *testing.T) {
 	gen := NewLocalGen()
-	words := [3]string{}
-	for i := 0; i < len(words); i++ {
-		words[i] = gen.Generate(Length)
-	}
-	if words[0] == words[1] && words[1] == words[2] {
-		t.Errorf("localWordGenerator.Generate() = %v, %v, %v on 3 consecutive generations; should be unique",
-			words[0], words[1], words[2])
+	for length := MinLength; length <= MaxLength; length++ {
+		t.Run(fmt.Sprintf("%d letters", length), func(t *testing.T) {
+			words := [3]string{}
+			for i := 0; i < len(words); i++ {
+				words[i] = gen.Generate(length)
+				assert.Len(t, words[i], length)
+			}
+			if words[0] == words[1] && words[1] == words[2] {
+				t.Errorf("localWordGenerator.Generate() = %v, %v, %v on 3 consecutive generations; should be unique",
+					words[0], words[1], words[2])
+			}
+		})
 	}
+	assert.Panics(t, func() { gen.Generate(MaxLength + 1) }, "unsupported lengths should not be generated")
 }
 
 func Test_localWordGenerator_Validate(t *testing.T) {
@@ -33,14 +41,14 @@ func Test_localWordGenerator_Validate(t *testing.T) {
 	words := []string{}
 	consonants := []rune{'B', 'C', 'D', 'F', 'G', 'H', 'J', 'K', 'L', 'M', 'N', 'P', 'Q', 'R', 'S', 'T', 'V', 'W', 'X', 'Y', 'Z'}
 	badWord := func() string {
-		rns := make([]rune, 0, Length)
-		for range Length {
+		rns := make([]rune, 0, DefaultLength)
+		for range DefaultLength {
 			rns = append(rns, consonants[rand.IntN(len(consonants))])
 		}
 		return string(rns)
 	}
 	for i := 0; i < correct; i++ {
-		words = append(words, gen.Generate(Length))
+		words = append(words, gen.Generate(MinLength+i))
 	}
 	for i := 0; i < incorrect; i++ {
 		words = append(words, badWord())
@@ -58,3 +66,25 @@ func Test_localWordGenerator_Validate(t *testing.T) {
 		})
 	}
 }
+
+func Test_localWordGenerator_Pick(t *testing.T) {
+	gen := NewLocalGen()
+	first := gen.Pick(DefaultLength, 42)
+	assert.Len(t, first, DefaultLength)
+	assert.True(t, gen.Validate(first))
+	assert.Equal(t, first, NewLocalGen().Pick(DefaultLength, 42), "the same seed should pick the same word")
+	assert.NotEqual(t, first, gen.Pick(DefaultLength, 43))
+}
+
+func Test_localWordGenerator_Answers(t *testing.T) {
+	gen := NewLocalGen()
+	// acronyms, brands and names that were once in the dictionaries
+	proper := []string{"AAAA", "ABEL", "ANSI", "ARAB", "ARPA", "EBAY", "GREG", "IPAD", "IPOD", "JOHN", "JULY", "JUNE", "LEGO", "PAUL", "PHIL", "TORY", "XBOX"}
+	for _, w := range gen.wordsArray[4] {
+		assert.Len(t, w, 4)
+		assert.NotContains(t, proper, w, "acronyms and proper nouns are not words to guess")
+	}
+	assert.True(t, gen.Validate("CATS"), "plurals are valid guesses")
+	assert.True(t, gen.Validate("DOGS"), "plurals are valid guesses")
+	assert.NotContains(t, gen.wordsArray[4], "CATS", "valid guesses are not words to guess")
+}
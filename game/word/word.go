@@ -3,6 +3,7 @@ package word
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"strings"
 )
 
@@ -80,17 +81,28 @@ func (w Word) group() (c, a int) {
 	return
 }
 
-// GreaterThan compares `w` with `other` returning true if `w` ranks higher than `other` otherwise false.
-// This function is similar to the `Less` function of the `sort.Interface` interface
-func (w Word) GreaterThan(other Word) bool {
+// Letters returns the number of letters of the word found at their position and the number of letters found at another position.
+func (w Word) Letters() (correct, exist int) {
+	return w.group()
+}
+
+// Compare compares the letters found by `w` and `other` without considering when they were played.
+// It returns a positive number if `w` ranks higher than `other`, a negative number if it ranks lower and zero otherwise.
+func (w Word) Compare(other Word) int {
 	thisCorrect, thisExist := w.group()
 	itCorrect, itExist := other.group()
 
 	if thisCorrect != itCorrect {
-		return thisCorrect > itCorrect
+		return thisCorrect - itCorrect
 	}
-	if thisExist != itExist {
-		return thisExist > itExist
+	return thisExist - itExist
+}
+
+// GreaterThan compares `w` with `other` returning true if `w` ranks higher than `other` otherwise false.
+// This function is similar to the `Less` function of the `sort.Interface` interface
+func (w Word) GreaterThan(other Word) bool {
+	if c := w.Compare(other); c != 0 {
+		return c > 0
 	}
 
 	if !w.PlayedAt.Valid {
@@ -155,3 +167,63 @@ func (w *Word) String() string {
 func (w Word) MarshalBinary() ([]byte, error) {
 	return json.Marshal(w)
 }
+
+// Hint constraints that can be violated by a guess in hard mode
+const (
+	// KeepCorrect requires letters found in their correct position to be kept in that position
+	KeepCorrect = "keep_correct"
+	// UseExisting requires letters found in the word to be used again
+	UseExisting = "use_existing"
+)
+
+// HintError is returned when a guess does not use the hints revealed by previous guesses.
+type HintError struct {
+	// Constraint is the hint constraint that was violated, KeepCorrect or UseExisting
+	Constraint string
+	Letter     rune
+	// Position is the index of the letter in the word when Constraint is KeepCorrect
+	Position int
+}
+
+func (e *HintError) Error() string {
+	if e.Constraint == KeepCorrect {
+		return fmt.Sprintf("letter %d must be %c", e.Position+1, e.Letter)
+	}
+	return fmt.Sprintf("guess must contain %c", e.Letter)
+}
+
+// UsesHints returns a *HintError if `w` does not keep the letters found in their correct position by the previous guesses
+// in that position, or if it leaves out letters found in the word by the previous guesses.
+// The previous guesses must have been checked against the correct word.
+func (w Word) UsesHints(previous []Word) error {
+	runes := w.Runes()
+	counts := make(map[rune]int)
+	for _, r := range runes {
+		counts[r]++
+	}
+	for _, p := range previous {
+		prevRunes := p.Runes()
+		required := make(map[rune]int)
+		for i, s := range p.Stats {
+			if i >= len(prevRunes) {
+				break
+			}
+			switch s {
+			case Correct:
+				if i >= len(runes) || runes[i] != prevRunes[i] {
+					return &HintError{Constraint: KeepCorrect, Letter: prevRunes[i], Position: i}
+				}
+				required[prevRunes[i]]++
+			case Exists:
+				required[prevRunes[i]]++
+			}
+		}
+		// letters are checked in the order of the previous guess to always report the same violation
+		for _, r := range prevRunes {
+			if counts[r] < required[r] {
+				return &HintError{Constraint: UseExisting, Letter: r}
+			}
+		}
+	}
+	return nil
+}
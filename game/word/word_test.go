@@ -58,3 +58,71 @@ func TestWord_Check(t *testing.T) {
 		})
 	}
 }
+
+func TestWord_Compare(t *testing.T) {
+	testCases := []struct {
+		word   string
+		other  string
+		expect int
+		desc   string
+	}{
+		{"WORLD", "WEIRD", 1, "more correct letters"},
+		{"WEIRD", "WORLD", -1, "less correct letters"},
+		{"DLROW", "SAVED", 1, "more existing letters"},
+		{"WORDS", "WORDY", 0, "same letters found"},
+	}
+	correctWord := New("WORLD")
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w, other := New(tt.word), New(tt.other)
+			w.Check(correctWord)
+			other.Check(correctWord)
+			got := w.Compare(other)
+			if (got > 0) != (tt.expect > 0) || (got < 0) != (tt.expect < 0) {
+				t.Errorf("Expected %d, got %d", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestWord_UsesHints(t *testing.T) {
+	testCases := []struct {
+		guess    string
+		previous []string
+		expected *HintError
+		desc     string
+	}{
+		{"SAVED", nil, nil, "first guess has no hints"},
+		{"SAVED", []string{"SEIZE"}, nil, "no hints revealed"},
+		{"WORLD", []string{"WEIRD"}, nil, "all hints used"},
+		{"WALRD", []string{"WEIRD"}, nil, "existing letter moved"},
+		{"SORLD", []string{"WEIRD"}, &HintError{Constraint: KeepCorrect, Letter: 'W', Position: 0}, "correct letter replaced"},
+		{"WOULD", []string{"WEIRD"}, &HintError{Constraint: UseExisting, Letter: 'R'}, "existing letter left out"},
+		{"WOLLD", []string{"LOROC"}, &HintError{Constraint: KeepCorrect, Letter: 'R', Position: 2}, "second correct letter replaced"},
+		{"WORLD", []string{"LOROC", "WEIRD"}, nil, "hints of every guess used"},
+		{"WOROD", []string{"LOROC", "WEIRD"}, &HintError{Constraint: UseExisting, Letter: 'L'}, "hint of earlier guess left out"},
+	}
+	correctWord := New("WORLD")
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			// given
+			previous := make([]Word, len(tt.previous))
+			for i, p := range tt.previous {
+				previous[i] = New(p)
+				previous[i].Check(correctWord)
+			}
+			// when
+			err := New(tt.guess).UsesHints(previous)
+			// then
+			if tt.expected == nil {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
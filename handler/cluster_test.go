@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/repository"
+	"github.com/kodekulture/wordle-server/repository/file"
+	"github.com/kodekulture/wordle-server/repository/memory"
+	"github.com/kodekulture/wordle-server/service"
+)
+
+// startInstance starts an instance of the server sharing the hub, the invites and the log with the other instances of the test.
+func startInstance(t *testing.T, hub *memory.Hub, invites *memory.Invites, wal repository.Log, opts ...service.Option) (*service.Service, *httptest.Server) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	srv := httptest.NewUnstartedServer(nil)
+	instance := "http://" + srv.Listener.Addr().String()
+	opts = append([]service.Option{service.WithInstance(instance)}, opts...)
+	s := service.New(ctx, nil, nil, nil, nil, hub, invites, wal, opts...)
+	srv.Config.Handler = New(s, nil).router
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+// newLog returns a log stored in the temporary directory of the test.
+func newLog(t *testing.T) repository.Log {
+	t.Helper()
+	wal, err := file.NewLog(t.TempDir())
+	require.NoError(t, err)
+	return wal
+}
+
+func TestCluster_Forward(t *testing.T) {
+	hub, invites, wal := memory.NewHub(), memory.NewInvites(), newLog(t)
+	a, srvA := startInstance(t, hub, invites, wal)
+	b, srvB := startInstance(t, hub, invites, wal)
+
+	id, err := a.NewRoom("fela", game.DefaultSettings())
+	require.NoError(t, err)
+	gameID := uuid.MustParse(id)
+
+	// the room is run by a only
+	_, ok := b.GetRoom(gameID)
+	assert.False(t, ok)
+	owner, remote := b.RoomOwner(gameID)
+	assert.True(t, remote)
+	assert.Equal(t, srvA.URL, owner)
+	_, remote = a.RoomOwner(gameID)
+	assert.False(t, remote)
+
+	// invites created by any instance are valid on every instance
+	token, err := b.CreateInvite(context.Background(), game.Player{Username: "fela"}, gameID, game.RolePlayer)
+	require.NoError(t, err)
+	_, invited, _, ok := a.GetInviteData(context.Background(), token)
+	require.True(t, ok)
+	assert.Equal(t, gameID, invited)
+
+	// b forwards the connection to a
+	u := "ws" + strings.TrimPrefix(srvB.URL, "http") + "/live?token=" + url.QueryEscape(token)
+	conn, _, err := websocket.DefaultDialer.Dial(u, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var payload game.Payload
+	for payload.Type != game.CData {
+		require.NoError(t, conn.ReadJSON(&payload))
+	}
+
+	// the invite was used once, by the owner of the room
+	_, resp, err := websocket.DefaultDialer.Dial(u, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCluster_RestoreLobby(t *testing.T) {
+	hub, invites, wal := memory.NewHub(), memory.NewInvites(), newLog(t)
+	a, srvA := startInstance(t, hub, invites, wal, service.WithInstance(""))
+
+	id, err := a.NewRoom("fela", game.DefaultSettings())
+	require.NoError(t, err)
+	gameID := uuid.MustParse(id)
+	token, err := a.CreateInvite(context.Background(), game.Player{Username: "fela", ID: 3}, gameID, game.RolePlayer)
+	require.NoError(t, err)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srvA.URL, "http")+"/live?token="+url.QueryEscape(token), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var payload game.Payload
+	for payload.Type != game.CData {
+		require.NoError(t, conn.ReadJSON(&payload))
+	}
+
+	// the lobby is restored by a new instance sharing the hub, as after a restart
+	b, _ := startInstance(t, hub, invites, wal, service.WithInstance(""))
+	room, ok := b.GetRoom(gameID)
+	require.True(t, ok)
+	t.Cleanup(room.Close)
+	g := room.Game()
+	assert.Nil(t, g.StartedAt)
+	assert.Equal(t, "fela", g.Creator)
+	require.Contains(t, g.Sessions, "fela")
+	assert.Equal(t, game.Player{Username: "fela", ID: 3}, g.Sessions["fela"].Player)
+	assert.NoError(t, room.CanJoin("james"), "players can join a restored lobby")
+}
+
+func TestCluster_RecoverFromLog(t *testing.T) {
+	invites, wal := memory.NewInvites(), newLog(t)
+	a, srvA := startInstance(t, memory.NewHub(), invites, wal, service.WithInstance(""))
+
+	id, err := a.NewRoom("fela", game.DefaultSettings())
+	require.NoError(t, err)
+	gameID := uuid.MustParse(id)
+	token, err := a.CreateInvite(context.Background(), game.Player{Username: "fela", ID: 3}, gameID, game.RolePlayer)
+	require.NoError(t, err)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srvA.URL, "http")+"/live?token="+url.QueryEscape(token), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var payload game.Payload
+	for payload.Type != game.CData {
+		require.NoError(t, conn.ReadJSON(&payload))
+	}
+
+	// the hub is lost when a single instance restarts without redis, the room is recovered from the log
+	hub := memory.NewHub()
+	b, _ := startInstance(t, hub, invites, wal, service.WithInstance(""))
+	room, ok := b.GetRoom(gameID)
+	require.True(t, ok)
+	t.Cleanup(room.Close)
+	g := room.Game()
+	assert.Equal(t, "fela", g.Creator)
+	require.Contains(t, g.Sessions, "fela")
+	assert.Equal(t, game.Player{Username: "fela", ID: 3}, g.Sessions["fela"].Player)
+	assert.True(t, hub.Exists(context.Background(), gameID), "the recovered game is stored in the hub again")
+}
+
+func TestLive_Resume(t *testing.T) {
+	s, srv := startInstance(t, memory.NewHub(), memory.NewInvites(), newLog(t), service.WithInstance(""))
+	id, err := s.NewRoom("fela", game.DefaultSettings())
+	require.NoError(t, err)
+	token, err := s.CreateInvite(context.Background(), game.Player{Username: "fela", ID: 3}, uuid.MustParse(id), game.RolePlayer)
+	require.NoError(t, err)
+	u := "ws" + strings.TrimPrefix(srv.URL, "http") + "/live?token=" + url.QueryEscape(token)
+
+	conn, _, err := websocket.DefaultDialer.Dial(u, nil)
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var data struct {
+		Type game.Event `json:"event"`
+		Data struct {
+			ResumeToken string `json:"resume_token"`
+		} `json:"data"`
+	}
+	for data.Type != game.CData {
+		require.NoError(t, conn.ReadJSON(&data))
+	}
+	require.NotEmpty(t, data.Data.ResumeToken)
+
+	// the invite is used up, unknown resume tokens do not skip it
+	_, resp, err := websocket.DefaultDialer.Dial(u+"&resume=unknown", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	require.NoError(t, conn.Close())
+	resumed, _, err := websocket.DefaultDialer.Dial(u+"&resume="+url.QueryEscape(data.Data.ResumeToken), nil)
+	require.NoError(t, err, "the player resumes the session with the resume token")
+	resumed.Close()
+}
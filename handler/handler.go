@@ -32,10 +32,12 @@ var (
 	})
 	// Create upgrade websocket connection
 	upgrader = websocket.Upgrader{
-		ReadBufferSize:  1024 * 1024,
-		WriteBufferSize: 1024 * 1024,
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
 		//Solving cross-domain problems
 		CheckOrigin: kors.OriginAllowed,
+		// Clients choose the codec of the messages with a subprotocol
+		Subprotocols: game.Subprotocols(),
 	}
 )
 
@@ -48,14 +50,21 @@ type Service interface {
 	UpdatePlayerSession(ctx context.Context, username string, sessionTs int64) error
 	GetPlayerRooms(ctx context.Context, playerID int) ([]game.Game, error)
 	GetGame(ctx context.Context, userID int, roomID uuid.UUID) (*game.Game, error)
-	GetInviteData(token string) (game.Player, uuid.UUID, bool)
+	GetInviteData(ctx context.Context, token string) (game.Player, uuid.UUID, game.Role, bool)
+	UseInvite(ctx context.Context, token string) error
+	GetMatch(ctx context.Context, id uuid.UUID) (*game.Match, error)
+
+	// Daily challenge ...
+	GetDaily(ctx context.Context, day time.Time) (*game.Game, error)
+	PlayDaily(ctx context.Context, player game.Player, guess string) (game.PlayerGuessResponse, error)
 
 	// Room ...
-	NewRoom(ownerUsername string) string
-	CreateInvite(player game.Player, gameID uuid.UUID) string
+	NewRoom(ownerUsername string, settings game.GameSettings) (string, error)
+	CreateInvite(ctx context.Context, player game.Player, gameID uuid.UUID, role game.Role) (string, error)
 
 	// Hub ...
 	GetRoom(id uuid.UUID) (*game.Room, bool)
+	RoomOwner(id uuid.UUID) (string, bool)
 }
 
 // Handler ...
@@ -108,6 +117,9 @@ func (h *Handler) setup() {
 		r.Get("/join/room/{id}", h.joinRoom)
 		r.Get("/room", h.rooms)
 		r.Get("/room/{id}", h.room)
+		r.Get("/match/{id}", h.match)
+		r.Get("/daily", h.daily)
+		r.Post("/daily/play", h.playDaily)
 		r.Post("/logout", h.logout)
 	})
 
@@ -259,7 +271,20 @@ func (h *Handler) createRoom(w http.ResponseWriter, r *http.Request) {
 		resp.Error(w, ErrUnauthenticated)
 		return
 	}
-	uid := h.srv.NewRoom(player.Username)
+	// 2. read the game settings, settings that are not provided keep their default values
+	settings := game.DefaultSettings()
+	defer r.Body.Close()
+	if r.ContentLength != 0 {
+		if err := req.I.Will().Bind(r, &settings).Err(); err != nil {
+			resp.Error(w, err)
+			return
+		}
+	}
+	uid, err := h.srv.NewRoom(player.Username, settings)
+	if err != nil {
+		resp.Error(w, err)
+		return
+	}
 	result := roomIDResponse{ID: uid}
 	resp.JSON(w, result)
 }
@@ -268,7 +293,8 @@ type joinRoomResponse struct {
 	Token string `json:"token"`
 }
 
-// joinRoom creates a new player token used to join a room using websocket connection
+// joinRoom creates a new player token used to join a room using websocket connection.
+// The room is joined as a spectator when the role query parameter is `spectator`.
 func (h *Handler) joinRoom(w http.ResponseWriter, r *http.Request) {
 	// get the user from the context
 	ctx := r.Context()
@@ -284,14 +310,28 @@ func (h *Handler) joinRoom(w http.ResponseWriter, r *http.Request) {
 		resp.Error(w, errs.B().Code(errs.InvalidArgument).Msg("invalid parameters").Err())
 		return
 	}
-	// find the room in the temporary area (Hub)
-	_, ok := h.srv.GetRoom(uid)
-	if !ok {
-		resp.Error(w, errs.B().Code(errs.NotFound).Msg("room not found").Err())
+	role := game.Role(r.URL.Query().Get("role"))
+	switch role {
+	case "":
+		role = game.RolePlayer
+	case game.RolePlayer, game.RoleSpectator:
+	default:
+		resp.Error(w, errs.B().Code(errs.InvalidArgument).Msg("invalid role").Err())
 		return
 	}
+	// find the room in the temporary area (Hub), it may be run by another instance
+	if _, ok := h.srv.GetRoom(uid); !ok {
+		if _, remote := h.srv.RoomOwner(uid); !remote {
+			resp.Error(w, errs.B().Code(errs.NotFound).Msg("room not found").Err())
+			return
+		}
+	}
 	// return a token for the user to join the room with ws
-	token := h.srv.CreateInvite(ptr.ToObj(player), uid)
+	token, err := h.srv.CreateInvite(ctx, ptr.ToObj(player), uid, role)
+	if err != nil {
+		resp.Error(w, err)
+		return
+	}
 	result := joinRoomResponse{Token: token}
 	resp.JSON(w, result)
 }
@@ -336,6 +376,77 @@ func (h *Handler) room(w http.ResponseWriter, r *http.Request) {
 	resp.JSON(w, game.ToResponse(ptr.ToObj(gm), player.Username))
 }
 
+// match returns the scores of a match and the rounds that have been played
+func (h *Handler) match(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	player := Player(ctx)
+	if player == nil {
+		resp.Error(w, ErrUnauthenticated)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		resp.Error(w, errs.B().Code(errs.InvalidArgument).Msg("invalid parameters").Err())
+		return
+	}
+	m, err := h.srv.GetMatch(ctx, uid)
+	if err != nil {
+		resp.Error(w, err)
+		return
+	}
+	resp.JSON(w, game.ToMatchResponse(ptr.ToObj(m)))
+}
+
+// daily returns the daily challenge of the day given in the query, or of today when it is not given
+func (h *Handler) daily(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	player := Player(ctx)
+	if player == nil {
+		resp.Error(w, ErrUnauthenticated)
+		return
+	}
+	day := time.Now()
+	if d := r.URL.Query().Get("day"); d != "" {
+		var err error
+		if day, err = time.Parse(time.DateOnly, d); err != nil {
+			resp.Error(w, errs.B().Code(errs.InvalidArgument).Msg("invalid parameters").Err())
+			return
+		}
+	}
+	gm, err := h.srv.GetDaily(ctx, day)
+	if err != nil {
+		resp.Error(w, err)
+		return
+	}
+	resp.JSON(w, game.ToResponse(ptr.ToObj(gm), player.Username))
+}
+
+type playDailyParams struct {
+	Word string `json:"word" validate:"required"`
+}
+
+func (h *Handler) playDaily(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	player := Player(ctx)
+	if player == nil {
+		resp.Error(w, ErrUnauthenticated)
+		return
+	}
+	var payload playDailyParams
+	defer r.Body.Close()
+	if err := req.I.Will().Bind(r, &payload).Validate(payload).Err(); err != nil {
+		resp.Error(w, err)
+		return
+	}
+	result, err := h.srv.PlayDaily(ctx, ptr.ToObj(player), payload.Word)
+	if err != nil {
+		resp.Error(w, err)
+		return
+	}
+	resp.JSON(w, result)
+}
+
 func (h *Handler) Stop(ctx context.Context) error {
 	return h.s.Shutdown(ctx)
 }
@@ -343,7 +454,7 @@ func (h *Handler) Stop(ctx context.Context) error {
 func (h *Handler) live(w http.ResponseWriter, r *http.Request) {
 	// Parse token from request query
 	token := r.URL.Query().Get("token")
-	p, gameID, ok := h.srv.GetInviteData(token)
+	p, gameID, role, ok := h.srv.GetInviteData(r.Context(), token)
 	if !ok {
 		resp.Error(w, errs.B().Code(errs.InvalidArgument).Msg("invalid token").Err())
 		return
@@ -351,16 +462,43 @@ func (h *Handler) live(w http.ResponseWriter, r *http.Request) {
 
 	room, ok := h.srv.GetRoom(gameID)
 	if !ok {
+		// the connection is forwarded to the instance running the room
+		if owner, remote := h.srv.RoomOwner(gameID); remote && r.Header.Get(forwardedHeader) == "" {
+			h.forward(w, r, owner)
+			return
+		}
 		resp.Error(w, errs.B().Code(errs.InvalidArgument).Msg("game not found").Err())
 		return
 	}
 
-	// Check if the game has started already and user has not joined
-	if err := room.CanJoin(p.Username); err != nil {
+	// Negotiate the version of the protocol used by the client
+	version, err := game.NegotiateVersion(r.URL.Query().Get("version"))
+	if err != nil {
 		resp.Error(w, errs.B(err).Code(errs.InvalidArgument).Err())
 		return
 	}
 
+	// Check if the game has started already and user has not joined, spectators can watch the game at any time
+	canJoin := room.CanJoin(p.Username)
+	if role == game.RoleSpectator {
+		canJoin = room.CanSpectate(p.Username)
+	}
+	if canJoin != nil {
+		resp.Error(w, errs.B(canJoin).Code(errs.InvalidArgument).Err())
+		return
+	}
+
+	// Every new connection uses the invite, players resuming their session are identified by their resume token.
+	// Connections with an unknown resume token join the room as new connections.
+	resume := r.URL.Query().Get("resume")
+	if role == game.RoleSpectator || !room.CanResume(p.Username, resume) {
+		resume = ""
+		if err = h.srv.UseInvite(r.Context(), token); err != nil {
+			resp.Error(w, err)
+			return
+		}
+	}
+
 	// Upgrade the HTTP connection to a websocket connection
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -368,5 +506,15 @@ func (h *Handler) live(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	room.Join(p, conn)
+	opts := []game.ConnOption{game.WithVersion(version), game.WithCodec(game.CodecFor(conn.Subprotocol()))}
+	if role == game.RoleSpectator {
+		room.Spectate(p, conn, opts...)
+		return
+	}
+	// Players whose connection dropped resume their session with the resume token they received
+	if resume != "" {
+		room.Resume(p, conn, resume, opts...)
+		return
+	}
+	room.Join(p, conn, opts...)
 }
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/lordvidex/errs/v2"
+	"github.com/lordvidex/x/resp"
+	"github.com/rs/zerolog/log"
+)
+
+// forwardedHeader marks requests forwarded by another instance, they are never forwarded again.
+const forwardedHeader = "X-Wordle-Forwarded"
+
+// forward proxies the request to the instance at `owner` that runs the room, websocket connections are proxied once upgraded.
+func (h *Handler) forward(w http.ResponseWriter, r *http.Request, owner string) {
+	target, err := url.Parse(owner)
+	if err != nil {
+		log.Err(err).Str("owner", owner).Msg("invalid instance address")
+		resp.Error(w, errs.B(err).Code(errs.Internal).Msg("room is unavailable").Err())
+		return
+	}
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Header.Set(forwardedHeader, "1")
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Err(err).Str("owner", owner).Msg("failed to forward request")
+			resp.Error(w, errs.B(err).Code(errs.Unavailable).Msg("room is unavailable").Err())
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
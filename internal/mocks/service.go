@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	uuid "github.com/google/uuid"
 	game "github.com/kodekulture/wordle-server/game"
@@ -81,17 +82,18 @@ func (c *MockServiceComparePasswordsCall) DoAndReturn(f func(string, string) err
 }
 
 // CreateInvite mocks base method.
-func (m *MockService) CreateInvite(player game.Player, gameID uuid.UUID) string {
+func (m *MockService) CreateInvite(ctx context.Context, player game.Player, gameID uuid.UUID, role game.Role) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateInvite", player, gameID)
+	ret := m.ctrl.Call(m, "CreateInvite", ctx, player, gameID, role)
 	ret0, _ := ret[0].(string)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateInvite indicates an expected call of CreateInvite.
-func (mr *MockServiceMockRecorder) CreateInvite(player, gameID any) *MockServiceCreateInviteCall {
+func (mr *MockServiceMockRecorder) CreateInvite(ctx, player, gameID, role any) *MockServiceCreateInviteCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvite", reflect.TypeOf((*MockService)(nil).CreateInvite), player, gameID)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvite", reflect.TypeOf((*MockService)(nil).CreateInvite), ctx, player, gameID, role)
 	return &MockServiceCreateInviteCall{Call: call}
 }
 
@@ -101,19 +103,19 @@ type MockServiceCreateInviteCall struct {
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockServiceCreateInviteCall) Return(arg0 string) *MockServiceCreateInviteCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockServiceCreateInviteCall) Return(arg0 string, arg1 error) *MockServiceCreateInviteCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockServiceCreateInviteCall) Do(f func(game.Player, uuid.UUID) string) *MockServiceCreateInviteCall {
+func (c *MockServiceCreateInviteCall) Do(f func(context.Context, game.Player, uuid.UUID, game.Role) (string, error)) *MockServiceCreateInviteCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockServiceCreateInviteCall) DoAndReturn(f func(game.Player, uuid.UUID) string) *MockServiceCreateInviteCall {
+func (c *MockServiceCreateInviteCall) DoAndReturn(f func(context.Context, game.Player, uuid.UUID, game.Role) (string, error)) *MockServiceCreateInviteCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -156,6 +158,45 @@ func (c *MockServiceCreatePlayerCall) DoAndReturn(f func(context.Context, *game.
 	return c
 }
 
+// GetDaily mocks base method.
+func (m *MockService) GetDaily(ctx context.Context, day time.Time) (*game.Game, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDaily", ctx, day)
+	ret0, _ := ret[0].(*game.Game)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDaily indicates an expected call of GetDaily.
+func (mr *MockServiceMockRecorder) GetDaily(ctx, day any) *MockServiceGetDailyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDaily", reflect.TypeOf((*MockService)(nil).GetDaily), ctx, day)
+	return &MockServiceGetDailyCall{Call: call}
+}
+
+// MockServiceGetDailyCall wrap *gomock.Call
+type MockServiceGetDailyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockServiceGetDailyCall) Return(arg0 *game.Game, arg1 error) *MockServiceGetDailyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockServiceGetDailyCall) Do(f func(context.Context, time.Time) (*game.Game, error)) *MockServiceGetDailyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockServiceGetDailyCall) DoAndReturn(f func(context.Context, time.Time) (*game.Game, error)) *MockServiceGetDailyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetGame mocks base method.
 func (m *MockService) GetGame(ctx context.Context, userID int, roomID uuid.UUID) (*game.Game, error) {
 	m.ctrl.T.Helper()
@@ -196,19 +237,20 @@ func (c *MockServiceGetGameCall) DoAndReturn(f func(context.Context, int, uuid.U
 }
 
 // GetInviteData mocks base method.
-func (m *MockService) GetInviteData(token string) (game.Player, uuid.UUID, bool) {
+func (m *MockService) GetInviteData(ctx context.Context, token string) (game.Player, uuid.UUID, game.Role, bool) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetInviteData", token)
+	ret := m.ctrl.Call(m, "GetInviteData", ctx, token)
 	ret0, _ := ret[0].(game.Player)
 	ret1, _ := ret[1].(uuid.UUID)
-	ret2, _ := ret[2].(bool)
-	return ret0, ret1, ret2
+	ret2, _ := ret[2].(game.Role)
+	ret3, _ := ret[3].(bool)
+	return ret0, ret1, ret2, ret3
 }
 
 // GetInviteData indicates an expected call of GetInviteData.
-func (mr *MockServiceMockRecorder) GetInviteData(token any) *MockServiceGetInviteDataCall {
+func (mr *MockServiceMockRecorder) GetInviteData(ctx, token any) *MockServiceGetInviteDataCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInviteData", reflect.TypeOf((*MockService)(nil).GetInviteData), token)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInviteData", reflect.TypeOf((*MockService)(nil).GetInviteData), ctx, token)
 	return &MockServiceGetInviteDataCall{Call: call}
 }
 
@@ -218,19 +260,58 @@ type MockServiceGetInviteDataCall struct {
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockServiceGetInviteDataCall) Return(arg0 game.Player, arg1 uuid.UUID, arg2 bool) *MockServiceGetInviteDataCall {
-	c.Call = c.Call.Return(arg0, arg1, arg2)
+func (c *MockServiceGetInviteDataCall) Return(arg0 game.Player, arg1 uuid.UUID, arg2 game.Role, arg3 bool) *MockServiceGetInviteDataCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2, arg3)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockServiceGetInviteDataCall) Do(f func(context.Context, string) (game.Player, uuid.UUID, game.Role, bool)) *MockServiceGetInviteDataCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockServiceGetInviteDataCall) DoAndReturn(f func(context.Context, string) (game.Player, uuid.UUID, game.Role, bool)) *MockServiceGetInviteDataCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetMatch mocks base method.
+func (m *MockService) GetMatch(ctx context.Context, id uuid.UUID) (*game.Match, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMatch", ctx, id)
+	ret0, _ := ret[0].(*game.Match)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMatch indicates an expected call of GetMatch.
+func (mr *MockServiceMockRecorder) GetMatch(ctx, id any) *MockServiceGetMatchCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatch", reflect.TypeOf((*MockService)(nil).GetMatch), ctx, id)
+	return &MockServiceGetMatchCall{Call: call}
+}
+
+// MockServiceGetMatchCall wrap *gomock.Call
+type MockServiceGetMatchCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockServiceGetMatchCall) Return(arg0 *game.Match, arg1 error) *MockServiceGetMatchCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockServiceGetInviteDataCall) Do(f func(string) (game.Player, uuid.UUID, bool)) *MockServiceGetInviteDataCall {
+func (c *MockServiceGetMatchCall) Do(f func(context.Context, uuid.UUID) (*game.Match, error)) *MockServiceGetMatchCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockServiceGetInviteDataCall) DoAndReturn(f func(string) (game.Player, uuid.UUID, bool)) *MockServiceGetInviteDataCall {
+func (c *MockServiceGetMatchCall) DoAndReturn(f func(context.Context, uuid.UUID) (*game.Match, error)) *MockServiceGetMatchCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -353,17 +434,18 @@ func (c *MockServiceGetRoomCall) DoAndReturn(f func(uuid.UUID) (*game.Room, bool
 }
 
 // NewRoom mocks base method.
-func (m *MockService) NewRoom(ownerUsername string) string {
+func (m *MockService) NewRoom(ownerUsername string, settings game.GameSettings) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewRoom", ownerUsername)
+	ret := m.ctrl.Call(m, "NewRoom", ownerUsername, settings)
 	ret0, _ := ret[0].(string)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // NewRoom indicates an expected call of NewRoom.
-func (mr *MockServiceMockRecorder) NewRoom(ownerUsername any) *MockServiceNewRoomCall {
+func (mr *MockServiceMockRecorder) NewRoom(ownerUsername, settings any) *MockServiceNewRoomCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewRoom", reflect.TypeOf((*MockService)(nil).NewRoom), ownerUsername)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewRoom", reflect.TypeOf((*MockService)(nil).NewRoom), ownerUsername, settings)
 	return &MockServiceNewRoomCall{Call: call}
 }
 
@@ -373,55 +455,97 @@ type MockServiceNewRoomCall struct {
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockServiceNewRoomCall) Return(arg0 string) *MockServiceNewRoomCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockServiceNewRoomCall) Return(arg0 string, arg1 error) *MockServiceNewRoomCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockServiceNewRoomCall) Do(f func(string) string) *MockServiceNewRoomCall {
+func (c *MockServiceNewRoomCall) Do(f func(string, game.GameSettings) (string, error)) *MockServiceNewRoomCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockServiceNewRoomCall) DoAndReturn(f func(string) string) *MockServiceNewRoomCall {
+func (c *MockServiceNewRoomCall) DoAndReturn(f func(string, game.GameSettings) (string, error)) *MockServiceNewRoomCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Stop mocks base method.
-func (m *MockService) Stop(arg0 context.Context) {
+// PlayDaily mocks base method.
+func (m *MockService) PlayDaily(ctx context.Context, player game.Player, guess string) (game.PlayerGuessResponse, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Stop", arg0)
+	ret := m.ctrl.Call(m, "PlayDaily", ctx, player, guess)
+	ret0, _ := ret[0].(game.PlayerGuessResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Stop indicates an expected call of Stop.
-func (mr *MockServiceMockRecorder) Stop(arg0 any) *MockServiceStopCall {
+// PlayDaily indicates an expected call of PlayDaily.
+func (mr *MockServiceMockRecorder) PlayDaily(ctx, player, guess any) *MockServicePlayDailyCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockService)(nil).Stop), arg0)
-	return &MockServiceStopCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlayDaily", reflect.TypeOf((*MockService)(nil).PlayDaily), ctx, player, guess)
+	return &MockServicePlayDailyCall{Call: call}
 }
 
-// MockServiceStopCall wrap *gomock.Call
-type MockServiceStopCall struct {
+// MockServicePlayDailyCall wrap *gomock.Call
+type MockServicePlayDailyCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockServiceStopCall) Return() *MockServiceStopCall {
-	c.Call = c.Call.Return()
+func (c *MockServicePlayDailyCall) Return(arg0 game.PlayerGuessResponse, arg1 error) *MockServicePlayDailyCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockServiceStopCall) Do(f func(context.Context)) *MockServiceStopCall {
+func (c *MockServicePlayDailyCall) Do(f func(context.Context, game.Player, string) (game.PlayerGuessResponse, error)) *MockServicePlayDailyCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockServiceStopCall) DoAndReturn(f func(context.Context)) *MockServiceStopCall {
+func (c *MockServicePlayDailyCall) DoAndReturn(f func(context.Context, game.Player, string) (game.PlayerGuessResponse, error)) *MockServicePlayDailyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RoomOwner mocks base method.
+func (m *MockService) RoomOwner(id uuid.UUID) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoomOwner", id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// RoomOwner indicates an expected call of RoomOwner.
+func (mr *MockServiceMockRecorder) RoomOwner(id any) *MockServiceRoomOwnerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoomOwner", reflect.TypeOf((*MockService)(nil).RoomOwner), id)
+	return &MockServiceRoomOwnerCall{Call: call}
+}
+
+// MockServiceRoomOwnerCall wrap *gomock.Call
+type MockServiceRoomOwnerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockServiceRoomOwnerCall) Return(arg0 string, arg1 bool) *MockServiceRoomOwnerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockServiceRoomOwnerCall) Do(f func(uuid.UUID) (string, bool)) *MockServiceRoomOwnerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockServiceRoomOwnerCall) DoAndReturn(f func(uuid.UUID) (string, bool)) *MockServiceRoomOwnerCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -463,3 +587,41 @@ func (c *MockServiceUpdatePlayerSessionCall) DoAndReturn(f func(context.Context,
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// UseInvite mocks base method.
+func (m *MockService) UseInvite(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseInvite", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseInvite indicates an expected call of UseInvite.
+func (mr *MockServiceMockRecorder) UseInvite(ctx, token any) *MockServiceUseInviteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseInvite", reflect.TypeOf((*MockService)(nil).UseInvite), ctx, token)
+	return &MockServiceUseInviteCall{Call: call}
+}
+
+// MockServiceUseInviteCall wrap *gomock.Call
+type MockServiceUseInviteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockServiceUseInviteCall) Return(arg0 error) *MockServiceUseInviteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockServiceUseInviteCall) Do(f func(context.Context, string) error) *MockServiceUseInviteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockServiceUseInviteCall) DoAndReturn(f func(context.Context, string) error) *MockServiceUseInviteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
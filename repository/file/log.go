@@ -0,0 +1,134 @@
+// Package file stores data of the application in files, it is used by single instance deployments and tests that run without Redis.
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+// Log implements repository.Log with a file per game, every record is written on its own line as JSON.
+// The records of a game are written under the lock of the game, so the games do not wait for the disk flushes of each other.
+type Log struct {
+	dir string
+
+	mu    sync.Mutex
+	locks map[uuid.UUID]*sync.Mutex
+}
+
+var _ repository.Log = (*Log)(nil)
+
+// NewLog returns a log storing its files in `dir`, the directory is created if it does not exist.
+func NewLog(dir string) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Log{dir: dir, locks: make(map[uuid.UUID]*sync.Mutex)}, nil
+}
+
+// lock locks the log of a game and returns the function unlocking it.
+func (l *Log) lock(gameID uuid.UUID) func() {
+	l.mu.Lock()
+	mu, ok := l.locks[gameID]
+	if !ok {
+		mu = new(sync.Mutex)
+		l.locks[gameID] = mu
+	}
+	l.mu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Append writes the record at the end of the log of the game, it returns once the record is synced to the disk.
+func (l *Log) Append(_ context.Context, gameID uuid.UUID, rec game.Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	defer l.lock(gameID)()
+	f, err := os.OpenFile(l.path(gameID), os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o640)
+	if err != nil {
+		return err
+	}
+	// a record torn when the server stopped is left on its own line
+	if torn, err := endsTorn(f); err != nil {
+		return errors.Join(err, f.Close())
+	} else if torn {
+		b = append([]byte{'\n'}, b...)
+	}
+	if _, err = f.Write(append(b, '\n')); err == nil {
+		err = f.Sync()
+	}
+	return errors.Join(err, f.Close())
+}
+
+// endsTorn returns true when the file does not end with a newline.
+func endsTorn(f *os.File) (bool, error) {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err = f.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+// Records reads the log of the game. Records that were not fully written when the server stopped are ignored,
+// they were never acknowledged.
+func (l *Log) Records(_ context.Context, gameID uuid.UUID) ([]game.Record, error) {
+	unlock := l.lock(gameID)
+	b, err := os.ReadFile(l.path(gameID))
+	unlock()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []game.Record
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(nil, len(b)+1)
+	for line := 1; sc.Scan(); line++ {
+		var rec game.Record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			log.Warn().Err(err).Str("game", gameID.String()).Int("line", line).Msg("skipping torn record")
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, sc.Err()
+}
+
+// Delete removes the log of the game.
+func (l *Log) Delete(_ context.Context, gameID uuid.UUID) error {
+	defer l.lock(gameID)()
+	defer func() {
+		l.mu.Lock()
+		delete(l.locks, gameID)
+		l.mu.Unlock()
+	}()
+	err := os.Remove(l.path(gameID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *Log) path(gameID uuid.UUID) string {
+	return filepath.Join(l.dir, gameID.String()+".log")
+}
@@ -0,0 +1,89 @@
+package file
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+func TestLog(t *testing.T) {
+	l, err := NewLog(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	g := game.New("fela", word.New("GAMES"))
+	records := []game.Record{
+		game.CreateRecord(g),
+		{Kind: game.RecordJoin, At: time.Now().UTC(), Player: &game.Player{Username: "fela", ID: 1}},
+		{Kind: game.RecordStart, At: time.Now().UTC()},
+	}
+	for _, rec := range records {
+		require.NoError(t, l.Append(ctx, g.ID, rec))
+	}
+	got, err := l.Records(ctx, g.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, game.RecordCreate, got[0].Kind)
+	assert.Equal(t, g.ID, got[0].Game.ID)
+	assert.Equal(t, "GAMES", got[0].Game.CorrectWord.Word)
+	assert.Equal(t, records[1].Player, got[1].Player)
+
+	// the server stopped while writing a record
+	f, err := os.OpenFile(l.path(g.ID), os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"kind":"guess","usern`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	got, err = l.Records(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Len(t, got, 3, "torn records are ignored")
+
+	require.NoError(t, l.Append(ctx, g.ID, game.Record{Kind: game.RecordGuess, Username: "fela", Word: "GAMER"}))
+	got, err = l.Records(ctx, g.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 4, "records are appended after a torn record")
+	assert.Equal(t, "GAMER", got[3].Word)
+
+	require.NoError(t, l.Delete(ctx, g.ID))
+	got, err = l.Records(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	assert.NoError(t, l.Delete(ctx, g.ID), "deleting a missing log does not fail")
+
+	got, err = l.Records(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestLog_Concurrent(t *testing.T) {
+	l, err := NewLog(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	games := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	var wg sync.WaitGroup
+	for _, id := range games {
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, l.Append(ctx, id, game.Record{Kind: game.RecordGuess, Username: "fela", Word: "GAMES"}))
+			}()
+		}
+	}
+	wg.Wait()
+	for _, id := range games {
+		got, err := l.Records(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, got, 10)
+	}
+}
@@ -0,0 +1,267 @@
+// Package memory stores the games being played in process memory, it stands in for redis in tests and single-node deployments.
+// Several instances of the server sharing a Hub behave as if they shared the same redis.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/game/word"
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+var (
+	ErrNoGame     = errors.New("game does not exist")
+	ErrGameExists = errors.New("game already exists")
+)
+
+type storedGame struct {
+	// game contains the metadata of the game without its sessions
+	game     game.Game
+	players  []game.Player
+	sessions map[string][]word.Word
+	// scores are the scores of the players on the leaderboard
+	scores map[string]float64
+}
+
+type storedMatch struct {
+	match   game.Match
+	current uuid.UUID
+}
+
+type lease struct {
+	instance  string
+	expiresAt time.Time
+}
+
+// Hub implements repository.Hub in memory.
+type Hub struct {
+	mu      sync.Mutex
+	games   map[uuid.UUID]*storedGame
+	events  map[uuid.UUID][]game.Payload
+	seqs    map[uuid.UUID]uint64
+	matches map[uuid.UUID]storedMatch
+	owners  map[uuid.UUID]lease
+}
+
+var _ repository.Hub = (*Hub)(nil)
+
+// NewHub ...
+func NewHub() *Hub {
+	return &Hub{
+		games:   make(map[uuid.UUID]*storedGame),
+		events:  make(map[uuid.UUID][]game.Payload),
+		seqs:    make(map[uuid.UUID]uint64),
+		matches: make(map[uuid.UUID]storedMatch),
+		owners:  make(map[uuid.UUID]lease),
+	}
+}
+
+// CreateGame ...
+func (h *Hub) CreateGame(_ context.Context, g *game.Game) error {
+	if g == nil {
+		return errors.New("nil game")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.games[g.ID]; ok {
+		return ErrGameExists
+	}
+	sg := &storedGame{game: metadata(g), players: members(g), sessions: make(map[string][]word.Word), scores: make(map[string]float64)}
+	for username, s := range g.Sessions {
+		sg.sessions[username] = slices.Clone(s.Guesses)
+	}
+	h.games[g.ID] = sg
+	return nil
+}
+
+// LoadGame ...
+func (h *Hub) LoadGame(_ context.Context, id uuid.UUID) (*game.Game, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sg, ok := h.games[id]
+	if !ok {
+		return nil, ErrNoGame
+	}
+	g := sg.game
+	g.Sessions = make(map[string]*game.Session, len(sg.players))
+	for _, p := range sg.players {
+		g.Sessions[p.Username] = &game.Session{Player: p, Guesses: slices.Clone(sg.sessions[p.Username])}
+	}
+	ranks := make([]string, 0, len(sg.scores))
+	for username := range sg.scores {
+		ranks = append(ranks, username)
+	}
+	slices.SortFunc(ranks, func(a, b string) int {
+		return cmp.Or(cmp.Compare(sg.scores[a], sg.scores[b]), strings.Compare(a, b))
+	})
+	g.Restore(ranks)
+	return &g, nil
+}
+
+// UpdateGame updates the metadata and the players of a game, the guesses of its players are not changed.
+func (h *Hub) UpdateGame(_ context.Context, g *game.Game) error {
+	if g == nil {
+		return errors.New("nil game")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sg, ok := h.games[g.ID]
+	if !ok {
+		return ErrNoGame
+	}
+	sg.game = metadata(g)
+	sg.players = members(g)
+	return nil
+}
+
+// DeleteGame ...
+func (h *Hub) DeleteGame(_ context.Context, id uuid.UUID) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.games, id)
+	return nil
+}
+
+// Exists ...
+func (h *Hub) Exists(_ context.Context, id uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.games[id]
+	return ok
+}
+
+// AddGuess ...
+func (h *Hub) AddGuess(_ context.Context, id uuid.UUID, player string, guess word.Word, score float64, isBest bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sg, ok := h.games[id]
+	if !ok {
+		return ErrNoGame
+	}
+	sg.sessions[player] = append(sg.sessions[player], guess)
+	if isBest {
+		sg.scores[player] = score
+	}
+	return nil
+}
+
+// AddEvent ...
+func (h *Hub) AddEvent(_ context.Context, id uuid.UUID, event game.Payload) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	events := append(h.events[id], event)
+	h.events[id] = events[max(0, len(events)-game.MaxHistory):]
+	h.seqs[id] = event.Seq
+	return nil
+}
+
+// SaveSeq ...
+func (h *Hub) SaveSeq(_ context.Context, id uuid.UUID, seq uint64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seqs[id] = seq
+	return nil
+}
+
+// LoadEvents ...
+func (h *Hub) LoadEvents(_ context.Context, id uuid.UUID) ([]game.Payload, uint64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.events[id]), h.seqs[id], nil
+}
+
+// SaveMatch ...
+func (h *Hub) SaveMatch(_ context.Context, m *game.Match, current uuid.UUID) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cp := *m
+	cp.Played = slices.Clone(m.Played)
+	cp.Scores = maps.Clone(m.Scores)
+	h.matches[m.ID] = storedMatch{match: cp, current: current}
+	return nil
+}
+
+// LoadMatch ...
+func (h *Hub) LoadMatch(_ context.Context, roomID uuid.UUID) (*game.Match, uuid.UUID, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sm, ok := h.matches[roomID]
+	if !ok {
+		return nil, uuid.Nil, nil
+	}
+	m := sm.match
+	m.Played = slices.Clone(sm.match.Played)
+	m.Scores = maps.Clone(sm.match.Scores)
+	return &m, sm.current, nil
+}
+
+// DeleteRoom ...
+func (h *Hub) DeleteRoom(_ context.Context, roomID uuid.UUID) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.events, roomID)
+	delete(h.seqs, roomID)
+	delete(h.matches, roomID)
+	return nil
+}
+
+// ClaimRoom ...
+func (h *Hub) ClaimRoom(_ context.Context, id uuid.UUID, instance string, ttl time.Duration) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	if l, ok := h.owners[id]; ok && l.expiresAt.After(now) && l.instance != instance {
+		return l.instance, nil
+	}
+	h.owners[id] = lease{instance: instance, expiresAt: now.Add(ttl)}
+	return instance, nil
+}
+
+// ReleaseRoom ...
+func (h *Hub) ReleaseRoom(_ context.Context, id uuid.UUID, instance string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.owners[id].instance == instance {
+		delete(h.owners, id)
+	}
+	return nil
+}
+
+// RoomOwner ...
+func (h *Hub) RoomOwner(_ context.Context, id uuid.UUID) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if l, ok := h.owners[id]; ok && l.expiresAt.After(time.Now()) {
+		return l.instance, nil
+	}
+	return "", nil
+}
+
+// members returns the players of a game without their password.
+func members(g *game.Game) []game.Player {
+	players := make([]game.Player, 0, len(g.Sessions))
+	for _, s := range g.Sessions {
+		p := s.Player
+		p.Password = ""
+		players = append(players, p)
+	}
+	return players
+}
+
+// metadata returns a copy of the game without its sessions, like the metadata stored in redis.
+func metadata(g *game.Game) game.Game {
+	cp := *g
+	cp.Sessions = nil
+	cp.Leaderboard = game.RankBoard{}
+	return cp
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+func TestHub_Game(t *testing.T) {
+	h := NewHub()
+	ctx := context.Background()
+	g := game.New("fela", word.New("GAMES"))
+	g.Join(game.Player{Username: "fela"})
+	g.Start()
+
+	require.NoError(t, h.CreateGame(ctx, g))
+	assert.ErrorIs(t, h.CreateGame(ctx, g), ErrGameExists)
+	assert.True(t, h.Exists(ctx, g.ID))
+
+	guess := word.New("GAMER")
+	require.NoError(t, h.AddGuess(ctx, g.ID, "fela", guess, g.Score("fela"), true))
+	loaded, err := h.LoadGame(ctx, g.ID)
+	require.NoError(t, err)
+	require.Contains(t, loaded.Sessions, "fela")
+	assert.Len(t, loaded.Sessions["fela"].Guesses, 1)
+	assert.NotSame(t, g, loaded, "games are copied in and out of the hub")
+
+	require.NoError(t, h.DeleteGame(ctx, g.ID))
+	_, err = h.LoadGame(ctx, g.ID)
+	assert.ErrorIs(t, err, ErrNoGame)
+}
+
+func TestHub_UpdateGame(t *testing.T) {
+	h := NewHub()
+	ctx := context.Background()
+	g := game.New("fela", word.New("GAMES"))
+	g.Join(game.Player{Username: "fela", ID: 1})
+	assert.ErrorIs(t, h.UpdateGame(ctx, g), ErrNoGame)
+	require.NoError(t, h.CreateGame(ctx, g))
+
+	g.Join(game.Player{Username: "ada", ID: 2, Password: "hash"})
+	g.Leave("fela")
+	require.NoError(t, h.UpdateGame(ctx, g))
+	loaded, err := h.LoadGame(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ada"}, loaded.Players())
+	assert.Equal(t, game.Player{Username: "ada", ID: 2}, loaded.Sessions["ada"].Player)
+}
+
+func TestHub_ClaimRoom(t *testing.T) {
+	h := NewHub()
+	ctx := context.Background()
+	id := uuid.New()
+
+	owner, err := h.ClaimRoom(ctx, id, "http://a", 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "http://a", owner)
+	owner, _ = h.ClaimRoom(ctx, id, "http://b", time.Minute)
+	assert.Equal(t, "http://a", owner, "the room is owned by the first instance")
+
+	require.NoError(t, h.ReleaseRoom(ctx, id, "http://b"))
+	owner, _ = h.RoomOwner(ctx, id)
+	assert.Equal(t, "http://a", owner, "only the owner releases the room")
+
+	time.Sleep(60 * time.Millisecond)
+	owner, _ = h.RoomOwner(ctx, id)
+	assert.Empty(t, owner, "the lease expires")
+	owner, _ = h.ClaimRoom(ctx, id, "http://b", time.Minute)
+	assert.Equal(t, "http://b", owner)
+}
+
+func TestHub_Match(t *testing.T) {
+	h := NewHub()
+	ctx := context.Background()
+	g := game.New("fela", word.New("GAMES"))
+	g.Settings.Rounds = 2
+	require.NoError(t, h.CreateGame(ctx, g))
+	require.NoError(t, h.AddEvent(ctx, g.ID, game.Payload{Type: game.CMessage, Data: "hello", Seq: 1}))
+
+	m := game.NewMatch(g)
+	m.Scores["fela"] = 1
+	next := uuid.New()
+	require.NoError(t, h.SaveMatch(ctx, m, next))
+	m.Scores["fela"] = 2
+	require.NoError(t, h.DeleteGame(ctx, g.ID))
+
+	loaded, current, err := h.LoadMatch(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Equal(t, next, current)
+	assert.Equal(t, map[string]int{"fela": 1}, loaded.Scores, "the match is copied")
+	require.NoError(t, h.SaveSeq(ctx, g.ID, 3))
+	events, seq, err := h.LoadEvents(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "the events of the room are kept when a round is deleted")
+	assert.EqualValues(t, 3, seq)
+
+	require.NoError(t, h.DeleteRoom(ctx, g.ID))
+	loaded, _, err = h.LoadMatch(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+	events, seq, err = h.LoadEvents(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Zero(t, seq)
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+type invite struct {
+	invite    repository.Invite
+	uses      int
+	expiresAt time.Time
+}
+
+// Invites implements repository.Invites in memory.
+type Invites struct {
+	mu      sync.Mutex
+	invites map[string]*invite
+}
+
+var _ repository.Invites = (*Invites)(nil)
+
+// NewInvites ...
+func NewInvites() *Invites {
+	return &Invites{invites: make(map[string]*invite)}
+}
+
+// SaveInvite stores the invite and drops the expired invites, invites that are not read after they expire are dropped this way.
+func (i *Invites) SaveInvite(_ context.Context, key string, inv repository.Invite, uses int, ttl time.Duration) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	now := time.Now()
+	maps.DeleteFunc(i.invites, func(_ string, inv *invite) bool {
+		return !inv.expiresAt.After(now)
+	})
+	i.invites[key] = &invite{invite: inv, uses: uses, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// LoadInvite ...
+func (i *Invites) LoadInvite(_ context.Context, key string) (repository.Invite, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	inv, err := i.get(key)
+	if err != nil {
+		return repository.Invite{}, err
+	}
+	return inv.invite, nil
+}
+
+// UseInvite ...
+func (i *Invites) UseInvite(_ context.Context, key string) (repository.Invite, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	inv, err := i.get(key)
+	if err != nil {
+		return repository.Invite{}, err
+	}
+	if inv.uses <= 0 {
+		return repository.Invite{}, repository.ErrInviteUsed
+	}
+	inv.uses--
+	return inv.invite, nil
+}
+
+// get returns an invite that has not expired, expired invites are deleted.
+func (i *Invites) get(key string) (*invite, error) {
+	inv, ok := i.invites[key]
+	if !ok {
+		return nil, repository.ErrNoInvite
+	}
+	if !inv.expiresAt.After(time.Now()) {
+		delete(i.invites, key)
+		return nil, repository.ErrNoInvite
+	}
+	return inv, nil
+}
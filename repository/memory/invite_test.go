@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+func TestInvites(t *testing.T) {
+	i := NewInvites()
+	ctx := context.Background()
+	invite := repository.Invite{Player: game.Player{Username: "fela"}, GameID: uuid.New(), Role: game.RolePlayer}
+
+	require.NoError(t, i.SaveInvite(ctx, "key", invite, 1, time.Minute))
+	got, err := i.UseInvite(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, invite, got)
+	_, err = i.UseInvite(ctx, "key")
+	assert.ErrorIs(t, err, repository.ErrInviteUsed)
+	got, err = i.LoadInvite(ctx, "key")
+	require.NoError(t, err, "invites without uses left are loaded until they expire")
+	assert.Equal(t, invite, got)
+
+	require.NoError(t, i.SaveInvite(ctx, "expired", invite, 1, 0))
+	_, err = i.UseInvite(ctx, "expired")
+	assert.ErrorIs(t, err, repository.ErrNoInvite)
+
+	require.NoError(t, i.SaveInvite(ctx, "unread", invite, 1, 0))
+	require.NoError(t, i.SaveInvite(ctx, "other", invite, 1, time.Minute))
+	assert.NotContains(t, i.invites, "unread", "expired invites are dropped when an invite is saved")
+	assert.Contains(t, i.invites, "key")
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/game/word"
+	"github.com/kodekulture/wordle-server/repository"
+	"github.com/kodekulture/wordle-server/repository/postgres/pgen"
+)
+
+var _ repository.Daily = new(DailyRepo)
+
+type DailyRepo struct {
+	*pgen.Queries
+}
+
+func NewDailyRepo(db pgen.DBTX) *DailyRepo {
+	return &DailyRepo{
+		pgen.New(db),
+	}
+}
+
+// GetSessions implements repository.Daily.
+func (r *DailyRepo) GetSessions(ctx context.Context, day time.Time) ([]*game.Session, error) {
+	players, err := r.DailyPlayers(ctx, pgtype.Date{Time: day, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*game.Session, len(players))
+	for i, p := range players {
+		var guesses []word.Word
+		if err = json.Unmarshal(p.PlayedWords, &guesses); err != nil {
+			return nil, err
+		}
+		sessions[i] = &game.Session{
+			Player: game.Player{
+				ID:       int(p.ID),
+				Username: p.Username,
+			},
+			Guesses: guesses,
+		}
+	}
+	return sessions, nil
+}
+
+// GetSession implements repository.Daily.
+func (r *DailyRepo) GetSession(ctx context.Context, day time.Time, player game.Player) (*game.Session, error) {
+	s := &game.Session{Player: game.Player{ID: player.ID, Username: player.Username}}
+	p, err := r.DailyPlayer(ctx, pgen.DailyPlayerParams{
+		Day:      pgtype.Date{Time: day, Valid: true},
+		PlayerID: int32(player.ID),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(p.PlayedWords, &s.Guesses); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SaveSession implements repository.Daily.
+// The session is only saved when its stored guesses were not changed since they were read, so that concurrent guesses
+// of a player on several instances can not play more guesses than allowed.
+func (r *DailyRepo) SaveSession(ctx context.Context, day time.Time, s *game.Session, played int) error {
+	var last word.Word
+	if len(s.Guesses) > 0 {
+		last = s.Guesses[len(s.Guesses)-1]
+	}
+	saved, err := r.SaveDailyPlayer(ctx, pgen.SaveDailyPlayerParams{
+		Day:         pgtype.Date{Time: day, Valid: true},
+		PlayerID:    int32(s.Player.ID),
+		PlayedWords: s.JSON(),
+		BestGuess: pgtype.Text{
+			String: s.BestGuess().Word,
+			Valid:  s.BestGuess().Word != "",
+		},
+		BestGuessTime: pgtype.Timestamptz{
+			Time:  s.BestGuess().PlayedAt.Time,
+			Valid: !s.BestGuess().PlayedAt.Time.IsZero(),
+		},
+		Finished: pgtype.Timestamptz{
+			Time:  last.PlayedAt.Time,
+			Valid: s.Ended(),
+		},
+		Played: int32(played),
+	})
+	if err != nil {
+		return err
+	}
+	if saved == 0 {
+		return repository.ErrSessionChanged
+	}
+	return nil
+}
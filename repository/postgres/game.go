@@ -50,12 +50,18 @@ func (r *GameRepo) StartGame(ctx context.Context, g *game.Game) error {
 		return err
 	}
 	// Create the game
+	rules := g.Rules()
 	err = r.q.WithTx(tx).CreateGame(ctx, pgen.CreateGameParams{
 		ID:          uid,
 		Creator:     player.ID,
 		CorrectWord: g.CorrectWord.Word,
 		CreatedAt:   pgtype.Timestamptz{Time: g.CreatedAt, Valid: true},
 		StartedAt:   pgtype.Timestamptz{Time: ptr.ToObj(g.StartedAt), Valid: g.StartedAt != nil},
+		Mode:        string(rules.Mode),
+		WordLength:  int16(rules.WordLength),
+		MaxGuesses:  int16(rules.MaxGuesses),
+		TimeLimit:   int32(rules.TimeLimit),
+		HardMode:    rules.HardMode,
 	})
 	if err != nil {
 		return err
@@ -138,7 +144,8 @@ func (r *GameRepo) FinishGame(ctx context.Context, g *game.Game) error {
 	if err != nil {
 		return err
 	}
-	// Update gamePlayers and set the played words
+	// Update gamePlayers and set the played words.
+	// The rank is read from the leaderboard which is ordered with the comparator of the game's mode.
 	for _, s := range g.Sessions {
 		r.q.WithTx(tx).UpdateGamePlayer(ctx, pgen.UpdateGamePlayerParams{
 			GameID:      gm.ID,
@@ -180,6 +187,7 @@ func (r *GameRepo) FetchGame(ctx context.Context, playerID int, gameID uuid.UUID
 		CreatedAt:   g.CreatedAt.Time,
 		StartedAt:   toNilTime(g.StartedAt),
 		EndedAt:     toNilTime(g.CreatedAt),
+		Settings:    toSettings(g.Mode, g.WordLength, g.MaxGuesses, g.TimeLimit, g.HardMode),
 	}
 
 	// fetch players
@@ -220,6 +228,17 @@ func (r *GameRepo) GetGames(ctx context.Context, playerID int) ([]game.Game, err
 	return result, nil
 }
 
+// SaveModeration implements repository.Game.
+func (r *GameRepo) SaveModeration(ctx context.Context, m game.Moderation) error {
+	return r.q.CreateModeration(ctx, pgen.CreateModerationParams{
+		GameID:    pgtype.UUID{Bytes: m.GameID, Valid: true},
+		Action:    string(m.Action),
+		CreatedAt: pgtype.Timestamptz{Time: m.CreatedAt, Valid: true},
+		Moderator: m.Moderator,
+		Target:    m.Target,
+	})
+}
+
 func toNilTime(t pgtype.Timestamptz) *time.Time {
 	if !t.Valid {
 		return nil
@@ -243,10 +262,21 @@ func toGame(g pgen.PlayerGamesRow) game.Game {
 		CreatedAt:   g.CreatedAt.Time,
 		StartedAt:   toNilTime(g.StartedAt),
 		EndedAt:     toNilTime(g.EndedAt),
+		Settings:    toSettings(g.Mode, g.WordLength, g.MaxGuesses, g.TimeLimit, g.HardMode),
 		// Sessions: -- sessions are not in the database
 	}
 }
 
+func toSettings(mode string, wordLength, maxGuesses int16, timeLimit int32, hardMode bool) game.GameSettings {
+	return game.GameSettings{
+		Mode:       game.Mode(mode),
+		WordLength: int(wordLength),
+		MaxGuesses: int(maxGuesses),
+		TimeLimit:  int(timeLimit),
+		HardMode:   hardMode,
+	}
+}
+
 func setSessions(gm *game.Game, allPlayers []pgen.GamePlayersRow, thisPlayer pgen.GamePlayerRow) {
 	rankBoard := game.RankBoard{
 		Positions: make(map[string]int, len(allPlayers)),
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lordvidex/errs/v2"
+	"github.com/lordvidex/x/ptr"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/repository"
+	"github.com/kodekulture/wordle-server/repository/postgres/pgen"
+)
+
+var _ repository.Match = new(MatchRepo)
+
+type MatchRepo struct {
+	db *pgxpool.Pool
+	q  *pgen.Queries
+}
+
+// SaveRound implements repository.Match.
+// The round of `g` must be the last round played in the match.
+func (r *MatchRepo) SaveRound(ctx context.Context, m *game.Match, g *game.Game) error {
+	if m == nil || g == nil {
+		return errs.B().Msg("match and game must not be nil in SaveRound").Err()
+	}
+	if len(m.Played) == 0 || m.Played[len(m.Played)-1].GameID != g.ID {
+		return errs.B().Msg("the game is not the last round of the match").Err()
+	}
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	// create a transaction
+	tx, err = r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	mid := pgtype.UUID{Bytes: m.ID, Valid: true}
+
+	// Get creator's ID
+	creator, err := r.q.WithTx(tx).FetchPlayerByUsername(ctx, m.Creator)
+	if err != nil {
+		return err
+	}
+	// Create the match with its first round or update it
+	err = r.q.WithTx(tx).SaveMatch(ctx, pgen.SaveMatchParams{
+		ID:        mid,
+		Creator:   creator.ID,
+		Rounds:    int16(m.Rounds),
+		CreatedAt: pgtype.Timestamptz{Time: m.CreatedAt, Valid: true},
+		EndedAt:   pgtype.Timestamptz{Time: ptr.ToObj(m.EndedAt), Valid: m.EndedAt != nil},
+	})
+	if err != nil {
+		return err
+	}
+	// Link the game to the match
+	err = r.q.WithTx(tx).CreateMatchRound(ctx, pgen.CreateMatchRoundParams{
+		MatchID: mid,
+		GameID:  pgtype.UUID{Bytes: g.ID, Valid: true},
+		Number:  int16(m.Played[len(m.Played)-1].Number),
+	})
+	if err != nil {
+		return err
+	}
+	// Update the points of the players of this round
+	for username, s := range g.Sessions {
+		err = r.q.WithTx(tx).SaveMatchPlayer(ctx, pgen.SaveMatchPlayerParams{
+			MatchID:  mid,
+			PlayerID: int32(s.Player.ID),
+			Points:   int32(m.Scores[username]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// FetchMatch implements repository.Match.
+func (r *MatchRepo) FetchMatch(ctx context.Context, id uuid.UUID) (*game.Match, error) {
+	mid := pgtype.UUID{Bytes: id, Valid: true}
+	m, err := r.q.FetchMatch(ctx, mid)
+	if err != nil {
+		return nil, err
+	}
+	match := &game.Match{
+		ID:        uuid.UUID(m.ID.Bytes),
+		Creator:   m.CreatorUsername,
+		Rounds:    int(m.Rounds),
+		CreatedAt: m.CreatedAt.Time,
+		EndedAt:   toNilTime(m.EndedAt),
+		Scores:    make(map[string]int),
+	}
+
+	// fetch rounds
+	rounds, err := r.q.MatchRounds(ctx, mid)
+	if err != nil {
+		return nil, err
+	}
+	match.Played = make([]game.Round, len(rounds))
+	for i, round := range rounds {
+		match.Played[i] = game.Round{
+			Number:      int(round.Number),
+			GameID:      uuid.UUID(round.ID.Bytes),
+			CorrectWord: round.CorrectWord,
+			EndedAt:     round.EndedAt.Time,
+		}
+	}
+
+	// fetch scores
+	players, err := r.q.MatchPlayers(ctx, mid)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range players {
+		match.Scores[p.Username] = int(p.Points)
+	}
+	return match, nil
+}
+
+func NewMatchRepo(db *pgxpool.Pool) *MatchRepo {
+	return &MatchRepo{
+		db: db,
+		q:  pgen.New(db),
+	}
+}
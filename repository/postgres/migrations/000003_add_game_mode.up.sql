@@ -0,0 +1 @@
+ALTER TABLE game ADD COLUMN mode VARCHAR(16) NOT NULL DEFAULT 'classic';
@@ -0,0 +1 @@
+ALTER TABLE game DROP COLUMN word_length;
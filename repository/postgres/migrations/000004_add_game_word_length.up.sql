@@ -0,0 +1 @@
+ALTER TABLE game ADD COLUMN word_length SMALLINT NOT NULL DEFAULT 5;
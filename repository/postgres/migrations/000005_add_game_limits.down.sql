@@ -0,0 +1,2 @@
+ALTER TABLE game DROP COLUMN time_limit;
+ALTER TABLE game DROP COLUMN max_guesses;
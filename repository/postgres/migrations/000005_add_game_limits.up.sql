@@ -0,0 +1,2 @@
+ALTER TABLE game ADD COLUMN max_guesses SMALLINT NOT NULL DEFAULT 6;
+ALTER TABLE game ADD COLUMN time_limit INTEGER NOT NULL DEFAULT 3600;
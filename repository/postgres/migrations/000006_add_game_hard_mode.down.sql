@@ -0,0 +1 @@
+ALTER TABLE game DROP COLUMN hard_mode;
@@ -0,0 +1 @@
+ALTER TABLE game ADD COLUMN hard_mode BOOLEAN NOT NULL DEFAULT FALSE;
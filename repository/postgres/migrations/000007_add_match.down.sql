@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS match_player;
+DROP TABLE IF EXISTS match_round;
+DROP TABLE IF EXISTS match;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS match (
+  id UUID PRIMARY KEY,
+  creator INTEGER NOT NULL REFERENCES player(id),
+  rounds SMALLINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  ended_at TIMESTAMPTZ
+);
+
+-- match<->game, each game played in a match is one of its rounds
+CREATE TABLE IF NOT EXISTS match_round (
+  match_id UUID NOT NULL REFERENCES match(id),
+  game_id UUID NOT NULL UNIQUE REFERENCES game(id),
+  -- the position of the round in the match starting from 1
+  number SMALLINT NOT NULL,
+  PRIMARY KEY (match_id, number)
+);
+
+-- match<->player
+CREATE TABLE IF NOT EXISTS match_player (
+  match_id UUID NOT NULL REFERENCES match(id),
+  player_id INTEGER NOT NULL REFERENCES player(id),
+  -- the points collected by this player in all the rounds played so far
+  points INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (match_id, player_id)
+);
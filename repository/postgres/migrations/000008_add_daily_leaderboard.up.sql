@@ -0,0 +1,12 @@
+-- daily challenge<->player, a player plays the daily challenge of a day once
+CREATE TABLE IF NOT EXISTS daily_leaderboard (
+  day DATE NOT NULL,
+  player_id INTEGER NOT NULL REFERENCES player(id),
+  --json data containing list of words played by this user
+  played_words JSONB NOT NULL,
+  best_guess VARCHAR(10),
+  best_guess_time TIMESTAMPTZ,
+  -- time he finished the daily challenge -- when null, this user is still playing
+  finished TIMESTAMPTZ,
+  PRIMARY KEY (day, player_id)
+);
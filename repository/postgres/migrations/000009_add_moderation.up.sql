@@ -0,0 +1,13 @@
+-- moderation actions taken by the creator of a game against its players
+CREATE TABLE IF NOT EXISTS moderation (
+  id SERIAL PRIMARY KEY,
+  -- games are only stored once they start, so the game is not referenced
+  game_id UUID NOT NULL,
+  -- kick, ban or transfer
+  action VARCHAR(16) NOT NULL,
+  moderator INTEGER NOT NULL REFERENCES player(id),
+  target INTEGER NOT NULL REFERENCES player(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS moderation_game_id_idx ON moderation (game_id);
@@ -12,7 +12,8 @@ import (
 )
 
 const createGame = `-- name: CreateGame :exec
-INSERT INTO game (id, creator, correct_word, created_at, started_at) VALUES ($1, $2, $3, $4, $5)
+INSERT INTO game (id, creator, correct_word, created_at, started_at, mode, word_length, max_guesses, time_limit, hard_mode)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 `
 
 type CreateGameParams struct {
@@ -21,6 +22,11 @@ type CreateGameParams struct {
 	CorrectWord string
 	CreatedAt   pgtype.Timestamptz
 	StartedAt   pgtype.Timestamptz
+	Mode        string
+	WordLength  int16
+	MaxGuesses  int16
+	TimeLimit   int32
+	HardMode    bool
 }
 
 func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) error {
@@ -30,6 +36,11 @@ func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) error {
 		arg.CorrectWord,
 		arg.CreatedAt,
 		arg.StartedAt,
+		arg.Mode,
+		arg.WordLength,
+		arg.MaxGuesses,
+		arg.TimeLimit,
+		arg.HardMode,
 	)
 	return err
 }
@@ -58,7 +69,7 @@ func (q *Queries) DeleteGamePlayers(ctx context.Context, gameID pgtype.UUID) err
 }
 
 const fetchGame = `-- name: FetchGame :one
-SELECT p.username AS creator_username, g.id, g.creator, g.correct_word, g.created_at, g.started_at, g.ended_at from game g
+SELECT p.username AS creator_username, g.id, g.creator, g.correct_word, g.created_at, g.started_at, g.ended_at, g.mode, g.word_length, g.max_guesses, g.time_limit, g.hard_mode from game g
 JOIN player p ON g.creator = p.id WHERE g.id = $1
 `
 
@@ -70,6 +81,11 @@ type FetchGameRow struct {
 	CreatedAt       pgtype.Timestamptz
 	StartedAt       pgtype.Timestamptz
 	EndedAt         pgtype.Timestamptz
+	Mode            string
+	WordLength      int16
+	MaxGuesses      int16
+	TimeLimit       int32
+	HardMode        bool
 }
 
 func (q *Queries) FetchGame(ctx context.Context, id pgtype.UUID) (FetchGameRow, error) {
@@ -83,6 +99,11 @@ func (q *Queries) FetchGame(ctx context.Context, id pgtype.UUID) (FetchGameRow,
 		&i.CreatedAt,
 		&i.StartedAt,
 		&i.EndedAt,
+		&i.Mode,
+		&i.WordLength,
+		&i.MaxGuesses,
+		&i.TimeLimit,
+		&i.HardMode,
 	)
 	return i, err
 }
@@ -189,7 +210,7 @@ func (q *Queries) GamePlayers(ctx context.Context, gameID pgtype.UUID) ([]GamePl
 }
 
 const playerGames = `-- name: PlayerGames :many
-SELECT g.id, g.correct_word, g.created_at, g.started_at, g.ended_at, 
+SELECT g.id, g.correct_word, g.created_at, g.started_at, g.ended_at, g.mode, g.word_length, g.max_guesses, g.time_limit, g.hard_mode,
   p.id AS creator_id, p.username AS creator_username,
   gp.player_id, gp.played_words, gp.best_guess, gp.best_guess_time, gp.finished, gp.rank
 FROM game g
@@ -212,6 +233,11 @@ type PlayerGamesRow struct {
 	CreatedAt       pgtype.Timestamptz
 	StartedAt       pgtype.Timestamptz
 	EndedAt         pgtype.Timestamptz
+	Mode            string
+	WordLength      int16
+	MaxGuesses      int16
+	TimeLimit       int32
+	HardMode        bool
 	CreatorID       int32
 	CreatorUsername string
 	PlayerID        int32
@@ -237,6 +263,11 @@ func (q *Queries) PlayerGames(ctx context.Context, arg PlayerGamesParams) ([]Pla
 			&i.CreatedAt,
 			&i.StartedAt,
 			&i.EndedAt,
+			&i.Mode,
+			&i.WordLength,
+			&i.MaxGuesses,
+			&i.TimeLimit,
+			&i.HardMode,
 			&i.CreatorID,
 			&i.CreatorUsername,
 			&i.PlayerID,
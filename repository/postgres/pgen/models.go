@@ -8,6 +8,15 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type DailyLeaderboard struct {
+	Day           pgtype.Date
+	PlayerID      int32
+	PlayedWords   []byte
+	BestGuess     pgtype.Text
+	BestGuessTime pgtype.Timestamptz
+	Finished      pgtype.Timestamptz
+}
+
 type Game struct {
 	ID          pgtype.UUID
 	Creator     int32
@@ -15,6 +24,11 @@ type Game struct {
 	CreatedAt   pgtype.Timestamptz
 	StartedAt   pgtype.Timestamptz
 	EndedAt     pgtype.Timestamptz
+	Mode        string
+	WordLength  int16
+	MaxGuesses  int16
+	TimeLimit   int32
+	HardMode    bool
 }
 
 type GamePlayer struct {
@@ -27,6 +41,35 @@ type GamePlayer struct {
 	Rank          pgtype.Int4
 }
 
+type Match struct {
+	ID        pgtype.UUID
+	Creator   int32
+	Rounds    int16
+	CreatedAt pgtype.Timestamptz
+	EndedAt   pgtype.Timestamptz
+}
+
+type MatchPlayer struct {
+	MatchID  pgtype.UUID
+	PlayerID int32
+	Points   int32
+}
+
+type MatchRound struct {
+	MatchID pgtype.UUID
+	GameID  pgtype.UUID
+	Number  int16
+}
+
+type Moderation struct {
+	ID        int32
+	GameID    pgtype.UUID
+	Action    string
+	Moderator int32
+	Target    int32
+	CreatedAt pgtype.Timestamptz
+}
+
 type Player struct {
 	ID        int32
 	Username  string
@@ -0,0 +1,17 @@
+-- name: DailyPlayers :many
+SELECT p.id, p.username, dl.played_words FROM daily_leaderboard dl
+JOIN player p ON dl.player_id = p.id
+WHERE dl.day = $1;
+
+-- name: DailyPlayer :one
+SELECT p.id, p.username, dl.played_words FROM daily_leaderboard dl
+JOIN player p ON dl.player_id = p.id
+WHERE dl.day = $1 AND dl.player_id = $2;
+
+-- name: SaveDailyPlayer :execrows
+INSERT INTO daily_leaderboard (day, player_id, played_words, best_guess, best_guess_time, finished)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (day, player_id) DO UPDATE
+SET played_words = EXCLUDED.played_words, best_guess = EXCLUDED.best_guess,
+  best_guess_time = EXCLUDED.best_guess_time, finished = EXCLUDED.finished
+WHERE jsonb_array_length(daily_leaderboard.played_words) = sqlc.arg(played)::int;
@@ -1,5 +1,5 @@
 -- name: PlayerGames :many
-SELECT g.id, g.correct_word, g.created_at, g.started_at, g.ended_at, 
+SELECT g.id, g.correct_word, g.created_at, g.started_at, g.ended_at, g.mode, g.word_length, g.max_guesses, g.time_limit, g.hard_mode,
   p.id AS creator_id, p.username AS creator_username,
   gp.player_id, gp.played_words, gp.best_guess, gp.best_guess_time, gp.finished, gp.rank
 FROM game g
@@ -38,7 +38,8 @@ UPDATE game_player SET played_words=$3, best_guess=$4, best_guess_time=$5, finis
 WHERE game_id=$1 AND player_id=$2;
 
 -- name: CreateGame :exec
-INSERT INTO game (id, creator, correct_word, created_at, started_at) VALUES ($1, $2, $3, $4, $5);
+INSERT INTO game (id, creator, correct_word, created_at, started_at, mode, word_length, max_guesses, time_limit, hard_mode)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
 
 -- name: DeleteGame :exec
 DELETE FROM game WHERE id = $1;
@@ -0,0 +1,26 @@
+-- name: SaveMatch :exec
+INSERT INTO match (id, creator, rounds, created_at, ended_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (id) DO UPDATE SET ended_at = EXCLUDED.ended_at;
+
+-- name: CreateMatchRound :exec
+INSERT INTO match_round (match_id, game_id, number) VALUES ($1, $2, $3);
+
+-- name: SaveMatchPlayer :exec
+INSERT INTO match_player (match_id, player_id, points) VALUES ($1, $2, $3)
+ON CONFLICT (match_id, player_id) DO UPDATE SET points = EXCLUDED.points;
+
+-- name: FetchMatch :one
+SELECT p.username AS creator_username, m.* FROM match m
+JOIN player p ON m.creator = p.id WHERE m.id = $1;
+
+-- name: MatchRounds :many
+SELECT mr.number, g.id, g.correct_word, g.ended_at FROM match_round mr
+JOIN game g ON mr.game_id = g.id
+WHERE mr.match_id = $1
+ORDER BY mr.number;
+
+-- name: MatchPlayers :many
+SELECT p.username, mp.points FROM match_player mp
+JOIN player p ON mp.player_id = p.id
+WHERE mp.match_id = $1;
@@ -0,0 +1,4 @@
+-- name: CreateModeration :exec
+INSERT INTO moderation (game_id, action, moderator, target, created_at)
+SELECT $1, $2, m.id, t.id, $3 FROM player m, player t
+WHERE m.username = sqlc.arg('moderator') AND t.username = sqlc.arg('target');
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	redis9 "github.com/redis/go-redis/v9"
+)
+
+// claimScript sets the owner of a room unless another instance owns it, and returns the owner of the room.
+var claimScript = redis9.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if not owner or owner == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return ARGV[1]
+end
+return owner
+`)
+
+// releaseScript deletes the owner of a room if it is the given instance.
+var releaseScript = redis9.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// ClaimRoom ...
+func (r GameRepository) ClaimRoom(ctx context.Context, roomID uuid.UUID, instance string, ttl time.Duration) (string, error) {
+	return claimScript.Run(ctx, r.cl, []string{owner(roomID)}, instance, ttl.Milliseconds()).Text()
+}
+
+// ReleaseRoom ...
+func (r GameRepository) ReleaseRoom(ctx context.Context, roomID uuid.UUID, instance string) error {
+	return releaseScript.Run(ctx, r.cl, []string{owner(roomID)}, instance).Err()
+}
+
+// RoomOwner ...
+func (r GameRepository) RoomOwner(ctx context.Context, roomID uuid.UUID) (string, error) {
+	res, err := r.cl.Get(ctx, owner(roomID)).Result()
+	if errors.Is(err, redis9.Nil) {
+		return "", nil
+	}
+	return res, err
+}
+
+// owner returns room:<id>:owner
+func owner(roomID uuid.UUID) string {
+	return keyed("room", roomID.String(), "owner")
+}
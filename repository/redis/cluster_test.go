@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	redis9 "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameRepository_ClaimRoom(t *testing.T) {
+	srv := miniredis.RunT(t)
+	r := NewGameRepo(redis9.NewClient(&redis9.Options{Addr: srv.Addr()}))
+	ctx := context.Background()
+	id := uuid.New()
+
+	owner, err := r.RoomOwner(ctx, id)
+	require.NoError(t, err)
+	assert.Empty(t, owner)
+
+	owner, err = r.ClaimRoom(ctx, id, "http://a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "http://a", owner)
+	owner, err = r.ClaimRoom(ctx, id, "http://b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "http://a", owner, "the room is owned by the first instance")
+
+	srv.FastForward(30 * time.Second)
+	_, err = r.ClaimRoom(ctx, id, "http://a", time.Minute)
+	require.NoError(t, err)
+	srv.FastForward(45 * time.Second)
+	owner, err = r.RoomOwner(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "http://a", owner, "the owner extends its lease by claiming the room again")
+
+	require.NoError(t, r.ReleaseRoom(ctx, id, "http://b"))
+	owner, _ = r.RoomOwner(ctx, id)
+	assert.Equal(t, "http://a", owner, "only the owner releases the room")
+	require.NoError(t, r.ReleaseRoom(ctx, id, "http://a"))
+	owner, err = r.ClaimRoom(ctx, id, "http://b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "http://b", owner)
+
+	srv.FastForward(2 * time.Minute)
+	owner, _ = r.RoomOwner(ctx, id)
+	assert.Empty(t, owner, "the lease expires")
+}
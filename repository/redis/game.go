@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,9 +22,43 @@ var (
 	ErrNoGame = errors.New("game does not exist")
 )
 
+// addGuessScript appends a guess to the session of a player and updates his score on the leaderboard when the guess is his best.
+// The session and the leaderboard expire with the game, the script does nothing when the game does not exist.
+//
+// KEYS: game, session, leaderboard. ARGV: guess, player, score or an empty string, default expiry in milliseconds.
+var addGuessScript = redis9.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl == -2 then
+	return 0
+end
+if ttl == -1 then
+	ttl = ARGV[4]
+end
+redis.call('RPUSH', KEYS[2], ARGV[1])
+redis.call('PEXPIRE', KEYS[2], ttl)
+if ARGV[3] ~= '' then
+	redis.call('ZADD', KEYS[3], ARGV[3], ARGV[2])
+	redis.call('PEXPIRE', KEYS[3], ttl)
+end
+return 1
+`)
+
 type Game struct {
 	Game    *game.Game
 	Players []string `json:"p"`
+	// Members are the players of the game, the sessions of a restored game belong to them
+	Members []game.Player `json:"m,omitempty"`
+}
+
+// newGame returns the metadata of a game
+func newGame(g *game.Game) Game {
+	rg := Game{Game: g, Players: g.Players()}
+	for _, username := range rg.Players {
+		p := g.Sessions[username].Player
+		p.Password = "" // the password hash is not needed to play
+		rg.Members = append(rg.Members, p)
+	}
+	return rg
 }
 
 // MarshalJSON returns JSON value of Game without Sessions and Leaderboards as they already exist in redis.
@@ -33,6 +68,7 @@ func (g Game) MarshalJSON() ([]byte, error) {
 
 	m := map[string]any{
 		"p":    g.Players,
+		"m":    g.Members,
 		"Game": &internal,
 	}
 	return json.Marshal(m)
@@ -61,9 +97,7 @@ func (r GameRepository) CreateGame(ctx context.Context, g *game.Game) error {
 	}
 
 	// create game metadata
-	players := g.Players()
-	rg := Game{Game: g, Players: players}
-	b, err := json.Marshal(rg)
+	b, err := json.Marshal(newGame(g))
 	if err != nil {
 		return err
 	}
@@ -71,6 +105,31 @@ func (r GameRepository) CreateGame(ctx context.Context, g *game.Game) error {
 	return r.cl.SetEx(ctx, gm(g.ID), string(b), GameExp).Err()
 }
 
+// UpdateGame updates the metadata and the players of a game that is already stored, the guesses of its players are not changed.
+// The expiry of the game is kept, except when the game starts: a game started late in its lobby is then stored for GameExp.
+func (r GameRepository) UpdateGame(ctx context.Context, g *game.Game) error {
+	if g == nil {
+		return errors.New("nil game")
+	}
+	stored, err := r.getGame(ctx, g.ID)
+	if errors.Is(err, redis9.Nil) {
+		return ErrNoGame
+	}
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(newGame(g))
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration = redis9.KeepTTL
+	if stored.Game.StartedAt == nil && g.StartedAt != nil {
+		ttl = GameExp
+	}
+	return r.cl.Set(ctx, gm(g.ID), string(b), ttl).Err()
+}
+
 // GetGame returns only game metadata without player's sessions
 func (r GameRepository) GetGame(ctx context.Context, gameID uuid.UUID) (*game.Game, error) {
 	g, err := r.getGame(ctx, gameID)
@@ -101,14 +160,14 @@ func (r GameRepository) DeleteGame(ctx context.Context, gameID uuid.UUID) error
 		return nil
 	}
 
-	keys := []string{gm(gameID), keyed(gm(gameID), "leaderboard")}
+	keys := []string{gm(gameID), ldb(gameID)}
 	for _, p := range rg.Players {
 		keys = append(keys, keyed(gm(gameID), ss(p)))
 	}
 	return r.cl.Del(ctx, keys...).Err()
 }
 
-// LoadGame loads full game data and resynced player sessions from storage
+// LoadGame loads full game data and resynced player sessions from storage, the players are ranked in the order of the stored leaderboard.
 func (r GameRepository) LoadGame(ctx context.Context, gameID uuid.UUID) (*game.Game, error) {
 	rg, err := r.getGame(ctx, gameID)
 	if err != nil {
@@ -120,13 +179,20 @@ func (r GameRepository) LoadGame(ctx context.Context, gameID uuid.UUID) (*game.G
 	if err != nil {
 		return nil, err
 	}
+	ranks, err := r.cl.ZRange(ctx, ldb(gameID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
 
-	ldb := game.NewRankBoard(sess)
+	for _, p := range rg.Members {
+		if s, ok := sess[p.Username]; ok {
+			s.Player = p
+		}
+	}
 
 	g := rg.Game
-	g.Leaderboard = ldb
 	g.Sessions = sess
-	g.Resync()
+	g.Restore(ranks)
 
 	return g, nil
 }
@@ -180,36 +246,98 @@ func (r GameRepository) Exists(ctx context.Context, gameID uuid.UUID) bool {
 	return res == 1
 }
 
-func (r GameRepository) AddGuess(ctx context.Context, gameID uuid.UUID, player string, guess word.Word, isBest bool) error {
-	if !r.Exists(ctx, gameID) {
+// AddGuess ...
+func (r GameRepository) AddGuess(ctx context.Context, gameID uuid.UUID, player string, guess word.Word, score float64, isBest bool) error {
+	var rank string // the score is only stored with the best guess
+	if isBest {
+		rank = strconv.FormatFloat(score, 'f', -1, 64)
+	}
+	keys := []string{gm(gameID), keyed(gm(gameID), ss(player)), ldb(gameID)}
+	added, err := addGuessScript.Run(ctx, r.cl, keys, guess, player, rank, GameExp.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if added == 0 {
 		return ErrNoGame
 	}
+	return nil
+}
 
-	_, err := r.cl.Pipelined(ctx, func(pipe redis9.Pipeliner) error {
-		err := pipe.RPush(ctx, keyed(gm(gameID), ss(player)), guess).Err()
-		if err != nil {
-			return err
-		}
-		err = pipe.ExpireLT(ctx, keyed(gm(gameID), ss(player)), GameExp).Err()
-		if err != nil {
-			return err
-		}
+// AddEvent ...
+func (r GameRepository) AddEvent(ctx context.Context, gameID uuid.UUID, event game.Payload) error {
+	b, err := game.MarshalEvent(event)
+	if err != nil {
+		return err
+	}
+	key := keyed(gm(gameID), "events")
+	_, err = r.cl.Pipelined(ctx, func(pipe redis9.Pipeliner) error {
+		pipe.RPush(ctx, key, b)
+		pipe.LTrim(ctx, key, -game.MaxHistory, -1)
+		pipe.Expire(ctx, key, GameExp)
+		pipe.Set(ctx, sq(gameID), event.Seq, GameExp)
+		return nil
+	})
+	return err
+}
 
-		if isBest {
-			err = pipe.HSet(ctx, keyed(gm(gameID), "leaderboard"), player, guess).Err()
-			if err != nil {
-				return err
-			}
-			err = pipe.ExpireLT(ctx, keyed(gm(gameID), "leaderboard"), GameExp).Err()
-			if err != nil {
-				return err
-			}
+// SaveSeq ...
+func (r GameRepository) SaveSeq(ctx context.Context, roomID uuid.UUID, seq uint64) error {
+	return r.cl.Set(ctx, sq(roomID), seq, GameExp).Err()
+}
+
+// LoadEvents ...
+func (r GameRepository) LoadEvents(ctx context.Context, gameID uuid.UUID) ([]game.Payload, uint64, error) {
+	res, err := r.cl.LRange(ctx, keyed(gm(gameID), "events"), 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	events := make([]game.Payload, len(res))
+	for i, e := range res {
+		if events[i], err = game.UnmarshalEvent([]byte(e)); err != nil {
+			return nil, 0, err
 		}
+	}
+	seq, err := r.cl.Get(ctx, sq(gameID)).Uint64()
+	if err != nil && !errors.Is(err, redis9.Nil) {
+		return nil, 0, err
+	}
+	return events, seq, nil
+}
 
-		return nil
-	})
+// match is a match stored with the id of the game of its current round
+type match struct {
+	Match   *game.Match `json:"match"`
+	Current uuid.UUID   `json:"current"`
+}
 
-	return err
+// SaveMatch ...
+func (r GameRepository) SaveMatch(ctx context.Context, m *game.Match, current uuid.UUID) error {
+	b, err := json.Marshal(match{Match: m, Current: current})
+	if err != nil {
+		return err
+	}
+	return r.cl.SetEx(ctx, mt(m.ID), b, GameExp).Err()
+}
+
+// LoadMatch ...
+func (r GameRepository) LoadMatch(ctx context.Context, roomID uuid.UUID) (*game.Match, uuid.UUID, error) {
+	b, err := r.cl.Get(ctx, mt(roomID)).Bytes()
+	if errors.Is(err, redis9.Nil) {
+		return nil, uuid.Nil, nil
+	}
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	var m match
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, uuid.Nil, err
+	}
+	return m.Match, m.Current, nil
+}
+
+// DeleteRoom ...
+func (r GameRepository) DeleteRoom(ctx context.Context, roomID uuid.UUID) error {
+	return r.cl.Del(ctx, keyed(gm(roomID), "events"), sq(roomID), mt(roomID)).Err()
 }
 
 func (r GameRepository) GetGuesses(ctx context.Context, gameID uuid.UUID, player string) ([]word.Word, error) {
@@ -235,19 +363,13 @@ func (r GameRepository) GetGuesses(ctx context.Context, gameID uuid.UUID, player
 }
 
 func (r GameRepository) GetBestGuess(ctx context.Context, gameID uuid.UUID, player string) (*word.Word, error) {
-	if !r.Exists(ctx, gameID) {
-		return nil, ErrNoGame
-	}
-
-	res, err := r.cl.HGet(ctx, keyed(gm(gameID), "leaderboard"), player).Result()
+	guesses, err := r.GetGuesses(ctx, gameID, player)
 	if err != nil {
 		return nil, err
 	}
-	var w word.Word
-	if err = json.Unmarshal([]byte(res), &w); err != nil {
-		return nil, err
-	}
-
+	s := &game.Session{Guesses: guesses}
+	s.Resync()
+	w := s.BestGuess()
 	return &w, nil
 }
 
@@ -264,3 +386,18 @@ func gm(gameID uuid.UUID) string {
 func ss(player string) string {
 	return keyed("session", player)
 }
+
+// mt returns game:<rid>:match, the match played in the room
+func mt(roomID uuid.UUID) string {
+	return keyed(gm(roomID), "match")
+}
+
+// sq returns game:<rid>:seq, the last sequence number handed out in the room
+func sq(roomID uuid.UUID) string {
+	return keyed(gm(roomID), "seq")
+}
+
+// ldb returns game:<gid>:ranks, the sorted set of the scores of the players
+func ldb(gameID uuid.UUID) string {
+	return keyed(gm(gameID), "ranks")
+}
@@ -1,8 +1,16 @@
 package redis
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	redis9 "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/kodekulture/wordle-server/game"
 	"github.com/kodekulture/wordle-server/game/word"
@@ -19,3 +27,157 @@ func TestGame_Marshal(t *testing.T) {
 	b, _ := json.Marshal(rg)
 	t.Log(string(b))
 }
+
+func TestGame_MarshalSettings(t *testing.T) {
+	g := game.New("test", word.New("EVADE"))
+	g.Settings.Mode = game.Sprint
+	g.Join(game.Player{Username: "test"})
+
+	b, err := json.Marshal(Game{Game: g, Players: g.Players()})
+	require.NoError(t, err)
+
+	var rg Game
+	require.NoError(t, json.Unmarshal(b, &rg))
+	assert.Equal(t, g.Settings, rg.Game.Settings)
+	assert.Equal(t, []string{"test"}, rg.Players)
+}
+
+func TestGameRepository_UpdateGame(t *testing.T) {
+	srv := miniredis.RunT(t)
+	r := NewGameRepo(redis9.NewClient(&redis9.Options{Addr: srv.Addr()}))
+	ctx := context.Background()
+
+	g := game.New("fela", word.New("EVADE"))
+	g.Join(game.Player{Username: "fela"})
+	g.Join(game.Player{Username: "james"})
+	assert.ErrorIs(t, r.UpdateGame(ctx, g), ErrNoGame)
+
+	require.NoError(t, r.CreateGame(ctx, g))
+	srv.FastForward(time.Minute)
+	g.Creator = "james"
+	g.Leave("fela")
+	g.Join(game.Player{Username: "ada", ID: 7, Password: "hash"})
+	require.NoError(t, r.UpdateGame(ctx, g))
+
+	stored, err := r.LoadGame(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "james", stored.Creator)
+	assert.ElementsMatch(t, []string{"james", "ada"}, stored.Players(), "the players of a lobby are updated")
+	assert.Equal(t, game.Player{Username: "ada", ID: 7}, stored.Sessions["ada"].Player, "players are stored without their password")
+	assert.Equal(t, GameExp-time.Minute, srv.TTL(gm(g.ID)), "the expiry of the game is kept")
+
+	g.Start()
+	require.NoError(t, r.UpdateGame(ctx, g))
+	assert.Equal(t, GameExp, srv.TTL(gm(g.ID)), "the expiry of the game is reset when it starts")
+}
+
+func TestGameRepository_Events(t *testing.T) {
+	srv := miniredis.RunT(t)
+	r := NewGameRepo(redis9.NewClient(&redis9.Options{Addr: srv.Addr()}))
+	ctx := context.Background()
+	id := game.New("fela", word.New("EVADE")).ID
+
+	for seq := uint64(1); seq <= game.MaxHistory+2; seq++ {
+		require.NoError(t, r.AddEvent(ctx, id, game.Payload{Type: game.CMessage, Data: "hello", From: "fela", Seq: seq}))
+	}
+	events, seq, err := r.LoadEvents(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, events, game.MaxHistory, "only the last events are kept")
+	assert.Equal(t, uint64(3), events[0].Seq)
+	assert.Equal(t, game.Payload{Type: game.CMessage, Data: "hello", From: "fela", Seq: game.MaxHistory + 2}, events[len(events)-1])
+	assert.EqualValues(t, game.MaxHistory+2, seq)
+	assert.Equal(t, GameExp, srv.TTL(keyed(gm(id), "events")))
+
+	require.NoError(t, r.SaveSeq(ctx, id, game.MaxHistory+5))
+	events, seq, err = r.LoadEvents(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, events, game.MaxHistory)
+	assert.EqualValues(t, game.MaxHistory+5, seq, "the sequence numbers of the events that are not stored are kept")
+	assert.Equal(t, GameExp, srv.TTL(sq(id)))
+}
+
+func TestGameRepository_Match(t *testing.T) {
+	srv := miniredis.RunT(t)
+	r := NewGameRepo(redis9.NewClient(&redis9.Options{Addr: srv.Addr()}))
+	ctx := context.Background()
+
+	first := game.New("fela", word.New("EVADE"))
+	first.Settings.Rounds = 3
+	first.Join(game.Player{Username: "fela"})
+	first.Start()
+	require.NoError(t, r.CreateGame(ctx, first))
+	require.NoError(t, r.AddEvent(ctx, first.ID, game.Payload{Type: game.CMessage, Data: "hello", Seq: 1}))
+
+	m, _, err := r.LoadMatch(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Nil(t, m, "no match is stored before the first round ends")
+
+	m = game.NewMatch(first)
+	m.Scores["fela"] = 1
+	m.Played = append(m.Played, game.Round{Number: 1, GameID: first.ID, CorrectWord: "EVADE"})
+	next := uuid.New()
+	require.NoError(t, r.SaveMatch(ctx, m, next))
+	require.NoError(t, r.DeleteGame(ctx, first.ID))
+
+	loaded, current, err := r.LoadMatch(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, next, current)
+	assert.Equal(t, m.Scores, loaded.Scores)
+	assert.Equal(t, m.Played[0].GameID, loaded.Played[0].GameID)
+	events, seq, err := r.LoadEvents(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "the events of the room are kept when its first round is deleted")
+	assert.EqualValues(t, 1, seq)
+
+	require.NoError(t, r.DeleteRoom(ctx, first.ID))
+	m, _, err = r.LoadMatch(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+	events, seq, err = r.LoadEvents(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Zero(t, seq)
+}
+
+func TestGameRepository_AddGuess(t *testing.T) {
+	srv := miniredis.RunT(t)
+	r := NewGameRepo(redis9.NewClient(&redis9.Options{Addr: srv.Addr()}))
+	ctx := context.Background()
+
+	g := game.New("fela", word.New("GAMES"))
+	for _, p := range []string{"fela", "james", "jane"} {
+		g.Join(game.Player{Username: p})
+	}
+	g.Start()
+	play := func(player, guess string) {
+		w := word.New(guess)
+		_, isBest, err := g.Play(player, &w)
+		require.NoError(t, err)
+		require.NoError(t, r.AddGuess(ctx, g.ID, player, w, g.Score(player), isBest))
+	}
+
+	w := word.New("JAMES")
+	assert.ErrorIs(t, r.AddGuess(ctx, g.ID, "fela", w, 0, true), ErrNoGame)
+	assert.False(t, srv.Exists(keyed(gm(g.ID), ss("fela"))), "guesses of a game that does not exist are not stored")
+
+	require.NoError(t, r.CreateGame(ctx, g))
+	srv.FastForward(time.Minute)
+	play("fela", "JAMES")
+	play("jane", "GAMAS")
+	play("fela", "HELLO")
+	play("james", "GAMES")
+	assert.Equal(t, GameExp-time.Minute, srv.TTL(keyed(gm(g.ID), ss("fela"))), "sessions expire with the game")
+	assert.Equal(t, GameExp-time.Minute, srv.TTL(ldb(g.ID)), "the leaderboard expires with the game")
+	members, err := srv.ZMembers(ldb(g.ID))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"james", "fela", "jane"}, members, "fela found as many letters as jane, earlier")
+
+	stored, err := r.LoadGame(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Equal(t, g.Leaderboard.Positions, stored.Leaderboard.Positions)
+	assert.Len(t, stored.Sessions["fela"].Guesses, 2)
+	assert.Equal(t, g.LeaderboardVersion(), stored.LeaderboardVersion())
+
+	require.NoError(t, r.DeleteGame(ctx, g.ID))
+	assert.False(t, srv.Exists(ldb(g.ID)))
+}
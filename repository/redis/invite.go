@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	redis9 "github.com/redis/go-redis/v9"
+
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+// useScript decrements the uses left of an invite and returns the invite, it returns 0 when the invite has no uses left.
+var useScript = redis9.NewScript(`
+local uses = redis.call('HGET', KEYS[1], 'uses')
+if not uses then
+	return nil
+end
+if tonumber(uses) <= 0 then
+	return 0
+end
+redis.call('HINCRBY', KEYS[1], 'uses', -1)
+return redis.call('HGET', KEYS[1], 'invite')
+`)
+
+// InviteRepository ...
+type InviteRepository struct {
+	cl *redis9.Client
+}
+
+var _ repository.Invites = (*InviteRepository)(nil)
+
+// NewInviteRepo ...
+func NewInviteRepo(cl *redis9.Client) *InviteRepository {
+	return &InviteRepository{
+		cl: cl,
+	}
+}
+
+// SaveInvite ...
+func (r InviteRepository) SaveInvite(ctx context.Context, key string, invite repository.Invite, uses int, ttl time.Duration) error {
+	b, err := json.Marshal(invite)
+	if err != nil {
+		return err
+	}
+	_, err = r.cl.TxPipelined(ctx, func(p redis9.Pipeliner) error {
+		p.HSet(ctx, inv(key), "invite", b, "uses", uses)
+		p.PExpire(ctx, inv(key), ttl)
+		return nil
+	})
+	return err
+}
+
+// LoadInvite ...
+func (r InviteRepository) LoadInvite(ctx context.Context, key string) (repository.Invite, error) {
+	res, err := r.cl.HGet(ctx, inv(key), "invite").Bytes()
+	if errors.Is(err, redis9.Nil) {
+		return repository.Invite{}, repository.ErrNoInvite
+	}
+	if err != nil {
+		return repository.Invite{}, err
+	}
+	var invite repository.Invite
+	err = json.Unmarshal(res, &invite)
+	return invite, err
+}
+
+// UseInvite ...
+func (r InviteRepository) UseInvite(ctx context.Context, key string) (repository.Invite, error) {
+	res, err := useScript.Run(ctx, r.cl, []string{inv(key)}).Result()
+	if errors.Is(err, redis9.Nil) {
+		return repository.Invite{}, repository.ErrNoInvite
+	}
+	if err != nil {
+		return repository.Invite{}, err
+	}
+	data, ok := res.(string)
+	if !ok {
+		return repository.Invite{}, repository.ErrInviteUsed
+	}
+	var invite repository.Invite
+	err = json.Unmarshal([]byte(data), &invite)
+	return invite, err
+}
+
+// inv returns invite:<key>
+func inv(key string) string {
+	return keyed("invite", key)
+}
@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	redis9 "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+func TestInviteRepository(t *testing.T) {
+	srv := miniredis.RunT(t)
+	r := NewInviteRepo(redis9.NewClient(&redis9.Options{Addr: srv.Addr()}))
+	ctx := context.Background()
+
+	invite := repository.Invite{Player: game.Player{Username: "fela", ID: 4}, GameID: uuid.New(), Role: game.RoleSpectator}
+	require.NoError(t, r.SaveInvite(ctx, "key", invite, 2, time.Minute))
+	for range 2 {
+		got, err := r.UseInvite(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, invite, got)
+	}
+	_, err := r.UseInvite(ctx, "key")
+	assert.ErrorIs(t, err, repository.ErrInviteUsed)
+	got, err := r.LoadInvite(ctx, "key")
+	require.NoError(t, err, "invites without uses left are loaded until they expire")
+	assert.Equal(t, invite, got)
+
+	_, err = r.UseInvite(ctx, "other")
+	assert.ErrorIs(t, err, repository.ErrNoInvite)
+
+	srv.FastForward(2 * time.Minute)
+	_, err = r.LoadInvite(ctx, "key")
+	assert.ErrorIs(t, err, repository.ErrNoInvite)
+}
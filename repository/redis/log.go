@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	redis9 "github.com/redis/go-redis/v9"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+// LogRepository ...
+type LogRepository struct {
+	cl *redis9.Client
+}
+
+var _ repository.Log = (*LogRepository)(nil)
+
+// NewLogRepo ...
+func NewLogRepo(cl *redis9.Client) *LogRepository {
+	return &LogRepository{
+		cl: cl,
+	}
+}
+
+// Append ...
+func (r LogRepository) Append(ctx context.Context, gameID uuid.UUID, rec game.Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = r.cl.TxPipelined(ctx, func(pipe redis9.Pipeliner) error {
+		pipe.RPush(ctx, wal(gameID), b)
+		pipe.Expire(ctx, wal(gameID), GameExp)
+		return nil
+	})
+	return err
+}
+
+// Records ...
+func (r LogRepository) Records(ctx context.Context, gameID uuid.UUID) ([]game.Record, error) {
+	res, err := r.cl.LRange(ctx, wal(gameID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]game.Record, len(res))
+	for i, rec := range res {
+		if err = json.Unmarshal([]byte(rec), &records[i]); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// Delete ...
+func (r LogRepository) Delete(ctx context.Context, gameID uuid.UUID) error {
+	return r.cl.Del(ctx, wal(gameID)).Err()
+}
+
+// wal returns game:<gid>:log, the list of the records of the game
+func wal(gameID uuid.UUID) string {
+	return keyed(gm(gameID), "log")
+}
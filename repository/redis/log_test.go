@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	redis9 "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/game/word"
+)
+
+func TestLogRepository(t *testing.T) {
+	srv := miniredis.RunT(t)
+	r := NewLogRepo(redis9.NewClient(&redis9.Options{Addr: srv.Addr()}))
+	ctx := context.Background()
+
+	g := game.New("fela", word.New("GAMES"))
+	require.NoError(t, r.Append(ctx, g.ID, game.CreateRecord(g)))
+	require.NoError(t, r.Append(ctx, g.ID, game.Record{Kind: game.RecordJoin, Player: &game.Player{Username: "fela", ID: 1}}))
+	records, err := r.Records(ctx, g.ID)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, g.ID, records[0].Game.ID)
+	assert.Equal(t, "fela", records[1].Player.Username)
+	assert.Equal(t, GameExp, srv.TTL(wal(g.ID)))
+
+	replayed, err := game.Replay(records)
+	require.NoError(t, err)
+	assert.Contains(t, replayed.Sessions, "fela")
+
+	require.NoError(t, r.Delete(ctx, g.ID))
+	records, err = r.Records(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
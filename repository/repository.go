@@ -3,6 +3,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -10,6 +12,13 @@ import (
 	"github.com/kodekulture/wordle-server/game/word"
 )
 
+var (
+	ErrNoInvite   = errors.New("invite does not exist")
+	ErrInviteUsed = errors.New("invite has no uses left")
+	// ErrSessionChanged is returned when a session was changed by another guess since it was read
+	ErrSessionChanged = errors.New("session was changed by another guess")
+)
+
 type Player interface {
 	// GetByUsername returns a player by username
 	GetByUsername(ctx context.Context, username string) (*game.Player, error)
@@ -38,12 +47,90 @@ type Game interface {
 	FetchGame(context.Context, int, uuid.UUID) (*game.Game, error)
 	// WipeGameData is used to delete abandoned games
 	WipeGameData(context.Context, uuid.UUID) error
+
+	// SaveModeration records a moderation action taken in the room of a game
+	SaveModeration(context.Context, game.Moderation) error
+}
+
+type Match interface {
+	// SaveRound saves a round of a match, the match is created with its first round.
+	// The game of the round must have been saved with Game.FinishGame.
+	SaveRound(ctx context.Context, m *game.Match, g *game.Game) error
+
+	// FetchMatch returns the match with the given ID and the rounds that have been played
+	FetchMatch(ctx context.Context, id uuid.UUID) (*game.Match, error)
+}
+
+type Daily interface {
+	// GetSessions returns the sessions of all the players who played the daily challenge of a day
+	GetSessions(ctx context.Context, day time.Time) ([]*game.Session, error)
+
+	// GetSession returns the session of a player in the daily challenge of a day, it has no guesses when the player has not played
+	GetSession(ctx context.Context, day time.Time, player game.Player) (*game.Session, error)
+
+	// SaveSession saves the session of a player in the daily challenge of a day if `played` guesses were stored before,
+	// ErrSessionChanged is returned when another guess was stored in the meantime
+	SaveSession(ctx context.Context, day time.Time, s *game.Session, played int) error
 }
 
 type Hub interface {
 	CreateGame(context.Context, *game.Game) error
+	// LoadGame returns a stored game with the sessions of its players, they are ranked in the order of the stored leaderboard
 	LoadGame(context.Context, uuid.UUID) (*game.Game, error)
+	// UpdateGame updates the metadata of a stored game
+	UpdateGame(context.Context, *game.Game) error
 	DeleteGame(context.Context, uuid.UUID) error
 	Exists(context.Context, uuid.UUID) bool
-	AddGuess(context.Context, uuid.UUID, string, word.Word, bool) error
+	// AddGuess appends a guess to the session of a player, the leaderboard keeps the `score` of the player when the guess is their best.
+	// It fails when the game is not stored.
+	AddGuess(ctx context.Context, gameID uuid.UUID, player string, guess word.Word, score float64, isBest bool) error
+	// AddEvent stores an event broadcast in the room of a game, only the last game.MaxHistory events are kept
+	AddEvent(context.Context, uuid.UUID, game.Payload) error
+	// SaveSeq stores the last sequence number handed out in the room of a game for an event that is not stored
+	SaveSeq(ctx context.Context, roomID uuid.UUID, seq uint64) error
+	// LoadEvents returns the last events broadcast in the room of a game ordered by their sequence number,
+	// and the last sequence number handed out in the room
+	LoadEvents(context.Context, uuid.UUID) ([]game.Payload, uint64, error)
+	// SaveMatch stores a match and the id of the game of its current round under the id of its room
+	SaveMatch(ctx context.Context, m *game.Match, current uuid.UUID) error
+	// LoadMatch returns the match played in a room and the id of the game of its current round.
+	// The match is nil when no round of the room has ended.
+	LoadMatch(ctx context.Context, roomID uuid.UUID) (*game.Match, uuid.UUID, error)
+	// DeleteRoom removes the events, the sequence number and the match stored for a room
+	DeleteRoom(ctx context.Context, roomID uuid.UUID) error
+
+	// ClaimRoom records `instance` as the owner of a room for `ttl` unless another instance owns it already,
+	// it returns the instance owning the room. The instance owning a room extends its lease by claiming it again.
+	ClaimRoom(ctx context.Context, roomID uuid.UUID, instance string, ttl time.Duration) (string, error)
+	// ReleaseRoom removes the lease of a room if it is owned by `instance`
+	ReleaseRoom(ctx context.Context, roomID uuid.UUID, instance string) error
+	// RoomOwner returns the instance owning a room, it is empty when no instance owns the room
+	RoomOwner(ctx context.Context, roomID uuid.UUID) (string, error)
+}
+
+// Invites stores the invites used to connect to the rooms, invites are stored under a key derived from their token.
+type Invites interface {
+	// SaveInvite stores an invite that can be used `uses` times until it expires after `ttl`
+	SaveInvite(ctx context.Context, key string, invite Invite, uses int, ttl time.Duration) error
+	// LoadInvite returns an invite that has not expired, even when it has no uses left
+	LoadInvite(ctx context.Context, key string) (Invite, error)
+	// UseInvite consumes a use of an invite and returns it, it fails when the invite has no uses left
+	UseInvite(ctx context.Context, key string) (Invite, error)
+}
+
+// Invite is the data of an invite token used to connect to the room of a game
+type Invite struct {
+	Player game.Player `json:"player"`
+	GameID uuid.UUID   `json:"game_id"`
+	Role   game.Role   `json:"role"`
+}
+
+// Log stores the append-only logs of the games being played, a game is rebuilt from its log with game.Replay.
+type Log interface {
+	// Append appends a record to the log of a game
+	Append(ctx context.Context, gameID uuid.UUID, rec game.Record) error
+	// Records returns the records of the log of a game in the order they were appended, it is empty when the game has no log
+	Records(ctx context.Context, gameID uuid.UUID) ([]game.Record, error)
+	// Delete removes the log of a game
+	Delete(ctx context.Context, gameID uuid.UUID) error
 }
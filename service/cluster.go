@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/kodekulture/wordle-server/internal/config"
+)
+
+var (
+	// leaseTTL is how long an instance owns a room without renewing its lease, leases are renewed three times per TTL
+	leaseTTL = config.GetOrDefault("ROOM_LEASE", 30*time.Second, time.ParseDuration)
+)
+
+// Option configures a Service
+type Option func(*Service)
+
+// WithInstance sets the address other instances of the server use to reach this instance, it is read from INSTANCE_URL by default.
+// Rooms are owned by the instance running them, requests for a room owned by another instance are forwarded to it.
+// Rooms are not leased when the address is empty, the server then runs as a single instance.
+func WithInstance(url string) Option {
+	return func(s *Service) { s.instance = url }
+}
+
+// Instance returns the address of this instance of the server
+func (s *Service) Instance() string {
+	return s.instance
+}
+
+// claimRoom leases the room to this instance, it returns false when the room is owned by another instance.
+func (s *Service) claimRoom(ctx context.Context, id uuid.UUID) (bool, error) {
+	if s.instance == "" {
+		return true, nil
+	}
+	owner, err := s.store.ClaimRoom(ctx, id, s.instance, leaseTTL)
+	if err != nil {
+		return false, err
+	}
+	return owner == s.instance, nil
+}
+
+// releaseRoom removes the lease of a room that is not run by this instance anymore.
+func (s *Service) releaseRoom(ctx context.Context, id uuid.UUID) {
+	if s.instance == "" {
+		return
+	}
+	if err := s.store.ReleaseRoom(ctx, id, s.instance); err != nil {
+		log.Err(err).Str("room", id.String()).Msg("failed to release room")
+	}
+}
+
+// evictRoom stops running a room whose lease was taken by another instance, its game is stored by that instance.
+// The players of the room are disconnected and connect again through the instance owning the room.
+func (s *Service) evictRoom(id uuid.UUID) {
+	r, ok := s.localStorage.GetRoom(id)
+	if !ok {
+		return
+	}
+	s.DeleteRoom(id)
+	r.Release()
+}
+
+// RoomOwner returns the address of the instance running a room when it is another instance.
+func (s *Service) RoomOwner(id uuid.UUID) (string, bool) {
+	if s.instance == "" {
+		return "", false
+	}
+	owner, err := s.store.RoomOwner(context.Background(), id)
+	if err != nil {
+		log.Err(err).Str("room", id.String()).Msg("failed to find room owner")
+		return "", false
+	}
+	return owner, owner != "" && owner != s.instance
+}
+
+// renewLeases extends the leases of the rooms run by this instance until the context is cancelled.
+func (s *Service) renewLeases(ctx context.Context) {
+	if s.instance == "" {
+		return
+	}
+	ticker := time.NewTicker(leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, id := range s.localStorage.roomIDs() {
+				owned, err := s.claimRoom(ctx, id)
+				if err != nil {
+					log.Err(err).Str("room", id.String()).Msg("failed to renew room lease")
+					continue
+				}
+				if !owned {
+					log.Warn().Str("room", id.String()).Msg("room is owned by another instance, releasing it")
+					s.evictRoom(id)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/repository/memory"
+)
+
+func TestCluster_LeaseTaken(t *testing.T) {
+	ttl := leaseTTL
+	leaseTTL = 60 * time.Millisecond
+	t.Cleanup(func() { leaseTTL = ttl })
+
+	ctx := context.Background()
+	hub := memory.NewHub()
+	s := newService(t, hub, fakeGames{}, nil, WithInstance("a"))
+	id, err := s.NewRoom("fela", game.DefaultSettings())
+	require.NoError(t, err)
+	roomID := uuid.MustParse(id)
+	room, ok := s.GetRoom(roomID)
+	require.True(t, ok)
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		room.Join(game.Player{Username: "fela"}, conn)
+	}))
+	t.Cleanup(srv.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var payload game.Payload
+	for payload.Type != game.CData {
+		require.NoError(t, conn.ReadJSON(&payload))
+	}
+
+	// another instance takes the room over, e.g. after the lease of this instance expired during a network partition
+	require.NoError(t, hub.ReleaseRoom(ctx, roomID, "a"))
+	_, err = hub.ClaimRoom(ctx, roomID, "b", time.Minute)
+	require.NoError(t, err)
+
+	for err == nil {
+		err = conn.ReadJSON(&payload)
+	}
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseServiceRestart), "the players connect again to the owner: %v", err)
+	_, ok = s.localStorage.GetRoom(roomID)
+	assert.False(t, ok, "the room is not served anymore")
+	_, ok = s.GetRoom(roomID)
+	assert.False(t, ok)
+	owner, remote := s.RoomOwner(roomID)
+	assert.True(t, remote)
+	assert.Equal(t, "b", owner)
+	assert.True(t, hub.Exists(ctx, roomID), "the lobby is kept for the owner")
+}
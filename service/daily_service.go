@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/lordvidex/errs/v2"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/game/word"
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+var (
+	ErrFutureDaily   = errs.B().Code(errs.InvalidArgument).Msg("the daily challenge of this day has not started").Err()
+	ErrDailyPlayed   = errs.B().Code(errs.FailedPrecondition).Msg("you have already played today's daily challenge").Err()
+	ErrInvalidGuess  = errs.B().Code(errs.InvalidArgument).Msg("the guess must be " + strconv.Itoa(word.DefaultLength) + " letters long").Err()
+	ErrUnknownWord   = errs.B().Code(errs.InvalidArgument).Msg("invalid english word").Err()
+	ErrDailyConflict = errs.B().Code(errs.Aborted).Msg("another guess was played at the same time, try again").Err()
+
+	letterRegexp = regexp.MustCompile("^[a-zA-Z]+$")
+)
+
+// dailySeed derives the seed used to pick the word of the daily challenge of `day` from the secret of the server,
+// so that the words of the next days can not be guessed from the words of the previous days.
+func dailySeed(secret []byte, day time.Time) uint64 {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(game.Day(day).Format(time.DateOnly)))
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+// dailyWord returns the word of the daily challenge of `day`.
+func (s *Service) dailyWord(day time.Time) word.Word {
+	return word.New(s.wordGen.Pick(word.DefaultLength, dailySeed(s.dailySecret, day)))
+}
+
+// GetDaily returns the game of the daily challenge of `day` with the sessions of the players who played it,
+// the correct word is only returned once the day is over.
+func (s *Service) GetDaily(ctx context.Context, day time.Time) (*game.Game, error) {
+	if day.After(time.Now()) {
+		return nil, ErrFutureDaily
+	}
+	sessions, err := s.dr.GetSessions(ctx, game.Day(day))
+	if err != nil {
+		return nil, errs.WrapCode(err, errs.Internal, "error fetching daily challenge")
+	}
+	return game.NewDaily(day, s.dailyWord(day), sessions), nil
+}
+
+// PlayDaily plays a guess of the player in the daily challenge of today, only the session of the player is read.
+// The leaderboard of the day is read with GetDaily.
+func (s *Service) PlayDaily(ctx context.Context, player game.Player, guess string) (game.PlayerGuessResponse, error) {
+	if len(guess) != word.DefaultLength || !letterRegexp.MatchString(guess) {
+		return game.PlayerGuessResponse{}, ErrInvalidGuess
+	}
+	w := word.New(guess)
+	if !s.ValidateWord(w.Word) {
+		return game.PlayerGuessResponse{}, ErrUnknownWord
+	}
+
+	today := game.Day(time.Now())
+	session, err := s.dr.GetSession(ctx, today, player)
+	if err != nil {
+		return game.PlayerGuessResponse{}, errs.WrapCode(err, errs.Internal, "error fetching daily challenge")
+	}
+	played := len(session.Guesses)
+	g := game.NewDaily(today, s.dailyWord(today), []*game.Session{session})
+	_, _, err = g.Play(player.Username, &w)
+	if errors.Is(err, game.ErrSessionEnded) {
+		return game.PlayerGuessResponse{}, ErrDailyPlayed
+	}
+	if err != nil {
+		return game.PlayerGuessResponse{}, errs.WrapCode(err, errs.Internal, "error playing daily challenge")
+	}
+	// the guess is only saved if no other guess of the player was saved since the session was read
+	err = s.dr.SaveSession(ctx, today, g.Sessions[player.Username], played)
+	if errors.Is(err, repository.ErrSessionChanged) {
+		return game.PlayerGuessResponse{}, ErrDailyConflict
+	}
+	if err != nil {
+		return game.PlayerGuessResponse{}, errs.WrapCode(err, errs.Internal, "error saving daily challenge")
+	}
+	return game.PlayerGuessResponse{
+		Result: game.ToGuess(w, true),
+	}, nil
+}
@@ -14,6 +14,7 @@ import (
 type coldStorage struct {
 	gr repository.Game
 	pr repository.Player
+	mr repository.Match
 	h  hasher.Bcrypt
 }
 
@@ -89,6 +90,30 @@ func (s *coldStorage) FinishGame(ctx context.Context, g *game.Game) error {
 	return nil
 }
 
-func newColdStorage(gr repository.Game, pr repository.Player) *coldStorage {
-	return &coldStorage{gr, pr, hasher.Bcrypt{}}
+func (s *coldStorage) SaveRound(ctx context.Context, m *game.Match, g *game.Game) error {
+	err := s.mr.SaveRound(ctx, m, g)
+	if err != nil {
+		return errs.WrapCode(err, errs.Internal, "error saving round of the match")
+	}
+	return nil
+}
+
+func (s *coldStorage) GetMatch(ctx context.Context, id uuid.UUID) (*game.Match, error) {
+	m, err := s.mr.FetchMatch(ctx, id)
+	if err != nil {
+		return nil, errs.WrapCode(err, errs.NotFound, "match not found")
+	}
+	return m, nil
+}
+
+func (s *coldStorage) Moderate(ctx context.Context, m game.Moderation) error {
+	err := s.gr.SaveModeration(ctx, m)
+	if err != nil {
+		return errs.WrapCode(err, errs.Internal, "error saving moderation")
+	}
+	return nil
+}
+
+func newColdStorage(gr repository.Game, pr repository.Player, mr repository.Match) *coldStorage {
+	return &coldStorage{gr, pr, mr, hasher.Bcrypt{}}
 }
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -13,11 +15,8 @@ import (
 )
 
 const (
-	// RoomDuration is the maximum time for a game, room is deleted and game data is wiped if
-	// game is not played during this time.
-	RoomDuration = time.Hour
-	// EmptyRoomDuration is the maximum time a game is left without players. It is shorter than RoomDuration
-	// because Room is probably not in use anymore and contains no data.
+	// EmptyRoomDuration is the maximum time a game is left without players. It can be shorter than the lobby timeout
+	// of the game because Room is probably not in use anymore and contains no data.
 	EmptyRoomDuration = time.Minute * 15
 )
 
@@ -49,6 +48,17 @@ func (s *localStorage) SetRoom(id uuid.UUID, r *game.Room) {
 	s.rooms[id] = r
 }
 
+// roomIDs returns the ids of the rooms run by this instance.
+func (s *localStorage) roomIDs() []uuid.UUID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]uuid.UUID, 0, len(s.rooms))
+	for id := range s.rooms {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 // DeleteRoom deletes the room with the given id.
 func (s *localStorage) DeleteRoom(id uuid.UUID) {
 	s.mu.Lock()
@@ -71,17 +81,26 @@ func (s *localStorage) gc(ctx context.Context) {
 
 	mark := func() {
 		garbage = nil
+		// the rooms are copied, because reading their game waits for their event loop, which can be deleting its room
 		s.mu.RLock()
-		defer s.mu.RUnlock()
+		rooms := slices.Collect(maps.Values(s.rooms))
+		s.mu.RUnlock()
 
-		for _, r := range s.rooms {
+		for _, r := range rooms {
 			g := r.Game()
 			if g == nil {
 				garbage = append(garbage, r)
 				continue
 			}
 
-			if time.Since(g.CreatedAt) >= RoomDuration {
+			rules := g.Rules()
+			// room is deleted and game data is wiped if game is not finished before its time limit
+			if g.IsActive() && time.Since(*g.StartedAt) >= rules.Duration() {
+				garbage = append(garbage, r)
+				continue
+			}
+
+			if !g.IsActive() && time.Since(g.CreatedAt) >= rules.LobbyDuration() {
 				garbage = append(garbage, r)
 				continue
 			}
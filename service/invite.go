@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lordvidex/errs/v2"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/internal/config"
+	"github.com/kodekulture/wordle-server/repository"
+)
+
+var (
+	// inviteTTL is how long an invite token can be used to connect to a room
+	inviteTTL = config.GetOrDefault("INVITE_TTL", time.Hour, time.ParseDuration)
+	// inviteUses is the number of connections that can be opened with an invite token, resuming a session does not use the token
+	inviteUses = config.GetOrDefault("INVITE_USES", 1, strconv.Atoi)
+
+	ErrInvalidInvite = errs.B().Code(errs.InvalidArgument).Msg("invalid token").Err()
+	ErrInviteUsed    = errs.B().Code(errs.InvalidArgument).Msg("token has already been used").Err()
+)
+
+// CreateInvite returns a new random token to connect to the room of a game.
+// Invites are shared by every instance, they expire after inviteTTL and can be used inviteUses times.
+func (s *Service) CreateInvite(ctx context.Context, player game.Player, gameID uuid.UUID, role game.Role) (string, error) {
+	token, err := s.r.Token()
+	if err != nil {
+		return "", errs.WrapCode(err, errs.Internal, "failed to generate token")
+	}
+	player.Password = "" // the password hash is not needed to play
+	invite := repository.Invite{Player: player, GameID: gameID, Role: role}
+	if err = s.invites.SaveInvite(ctx, s.r.Key(token), invite, inviteUses, inviteTTL); err != nil {
+		return "", errs.WrapCode(err, errs.Internal, "failed to store invite")
+	}
+	return token, nil
+}
+
+// GetInviteData returns the invite of a token without using it.
+func (s *Service) GetInviteData(ctx context.Context, token string) (game.Player, uuid.UUID, game.Role, bool) {
+	invite, err := s.invites.LoadInvite(ctx, s.r.Key(token))
+	if err != nil {
+		return game.Player{}, uuid.Nil, "", false
+	}
+	return invite.Player, invite.GameID, invite.Role, true
+}
+
+// UseInvite uses the invite of a token to open a connection.
+func (s *Service) UseInvite(ctx context.Context, token string) error {
+	_, err := s.invites.UseInvite(ctx, s.r.Key(token))
+	switch {
+	case errors.Is(err, repository.ErrNoInvite):
+		return ErrInvalidInvite
+	case errors.Is(err, repository.ErrInviteUsed):
+		return ErrInviteUsed
+	case err != nil:
+		return errs.WrapCode(err, errs.Internal, "failed to use invite")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/kodekulture/wordle-server/game"
+)
+
+// loadGame rebuilds a game from the records of its log, games without records are loaded from the hub.
+// A game recovered from its log is stored in the hub again when the hub lost it, so that the next guesses can be stored.
+func (s *Service) loadGame(ctx context.Context, id uuid.UUID, records []game.Record) (*game.Game, error) {
+	if len(records) == 0 {
+		return s.store.LoadGame(ctx, id)
+	}
+	g, err := game.Replay(records)
+	if err != nil {
+		return nil, err
+	}
+	if !s.store.Exists(ctx, id) {
+		if err = s.store.CreateGame(ctx, g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// deleteLog removes the log of a game that is not played anymore.
+func (s *Service) deleteLog(ctx context.Context, id uuid.UUID) {
+	if err := s.wal.Delete(ctx, id); err != nil {
+		log.Err(err).Str("game", id.String()).Msg("failed to delete the log of the game")
+	}
+}
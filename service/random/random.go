@@ -1,91 +1,38 @@
 package random
 
 import (
-	"context"
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
-	"sync"
-	"time"
-
-	"github.com/google/uuid"
-	"github.com/kodekulture/wordle-server/game"
-)
-
-var (
-	valueMaxLife = time.Hour
-	cleanupCycle = time.Minute
-	salt         = "NdZXxlv1ShnypBDGrJCRe8g7HPENVyXkSZyOsSYyQbGtqnduoxMPyfcnKXEVKdHz" // TODO: load from env
 )
 
-type value struct {
-	createdAt time.Time
-	player    game.Player
-	gameID    uuid.UUID
-}
+// tokenSize is the number of random bytes of an invite token
+const tokenSize = 32
 
+// RandomGen generates the tokens of invites and the keys they are stored with.
 type RandomGen struct {
-	mu *sync.RWMutex
-	s  map[string]value
+	secret []byte
 }
 
-// New returns a new RandomGen
-func New(ctx context.Context) RandomGen {
-	r := RandomGen{s: make(map[string]value), mu: new(sync.RWMutex)}
-	go r.cleanup(ctx)
-	return r
+// New returns a new RandomGen, the keys of the tokens are signed with `secret`
+func New(secret []byte) RandomGen {
+	return RandomGen{secret: secret}
 }
 
-// Store stores the username and gameID associated with a the token and returns it
-func (rg RandomGen) Store(player game.Player, gameID uuid.UUID) string {
-	hash256 := sha256.New()
-	data := player.Username + salt + gameID.String()
-	hash256.Write([]byte(data))
-	token := hex.EncodeToString(hash256.Sum(nil))
-
-	rg.mu.Lock()
-	defer rg.mu.Unlock()
-
-	// if the user already issued the token
-	if _, ok := rg.s[token]; ok {
-		return token
+// Token returns a new random token
+func (RandomGen) Token() (string, error) {
+	b := make([]byte, tokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-	rg.s[token] = value{
-		player:    player,
-		gameID:    gameID,
-		createdAt: time.Now(),
-	}
-	return token
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-// Get returns the username and gameID associated with the token
-func (r RandomGen) Get(token string) (game.Player, uuid.UUID, bool) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	v, ok := r.s[token]
-	if !ok {
-		return game.Player{}, uuid.Nil, false
-	}
-	return v.player, v.gameID, true
-}
-
-// cleanup removes all the values that are older than valueMaxLife
-// since the game is not supposed to last more than one hour
-func (r RandomGen) cleanup(ctx context.Context) {
-	ticker := time.NewTicker(cleanupCycle)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			now := time.Now()
-			r.mu.Lock()
-			for k, v := range r.s {
-				if now.Sub(v.createdAt) > valueMaxLife {
-					delete(r.s, k)
-				}
-			}
-			r.mu.Unlock()
-		}
-	}
+// Key returns the key a token is stored with, tokens cannot be recovered from the stored keys without the secret.
+func (g RandomGen) Key(token string) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lordvidex/errs/v2"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/kodekulture/wordle-server/game"
 	"github.com/kodekulture/wordle-server/game/word"
+	"github.com/kodekulture/wordle-server/internal/config"
 	"github.com/kodekulture/wordle-server/repository"
 	"github.com/kodekulture/wordle-server/service/random"
 )
@@ -25,16 +27,39 @@ type Service struct {
 	r       random.RandomGen
 	wordGen word.Generator
 	store   repository.Hub
+	invites repository.Invites
+	// wal is the append-only log of the games being played, rooms are recovered from it
+	wal repository.Log
+	// instance is the address of this instance of the server, see WithInstance
+	instance string
+
+	dr          repository.Daily
+	dailySecret []byte // dailySecret is used to pick the word of the daily challenge
 }
 
-// NewRoom creates a new room and returns the id of the game that is currently running in this room
-func (s *Service) NewRoom(username string) string {
-	wrd := s.wordGen.Generate(word.Length)
-	log.Debug().Msg(wrd) // TODO: remove this on production, for now leave it for debugging
-	g := game.New(username, word.New(wrd))
+// NewRoom creates a new room played with the given settings and returns the id of the game that is currently running in this room.
+// The game is stored in the hub with its lobby, so that the room can be restored before the game starts.
+func (s *Service) NewRoom(username string, settings game.GameSettings) (string, error) {
+	if err := settings.Validate(); err != nil {
+		return "", errs.WrapCode(err, errs.InvalidArgument, "invalid game settings")
+	}
+	ctx := context.Background()
+	g := game.New(username, word.New(s.GenerateWord(settings.WordLength)))
+	g.Settings = settings
+	if _, err := s.claimRoom(ctx, g.ID); err != nil {
+		return "", errs.WrapCode(err, errs.Internal, "failed to lease room")
+	}
+	if err := s.store.CreateGame(ctx, g); err != nil {
+		s.releaseRoom(ctx, g.ID)
+		return "", errs.WrapCode(err, errs.Internal, "failed to store room")
+	}
+	if err := s.wal.Append(ctx, g.ID, game.CreateRecord(g)); err != nil {
+		s.releaseRoom(ctx, g.ID)
+		return "", errs.WrapCode(err, errs.Internal, "failed to store room")
+	}
 	room := game.NewRoom(g, s)
 	s.SetRoom(g.ID, room)
-	return room.ID()
+	return room.ID(), nil
 }
 
 // StartGame ...
@@ -43,6 +68,10 @@ func (s *Service) StartGame(ctx context.Context, g *game.Game) error {
 	if err != nil {
 		return err
 	}
+	// the lobby of the game is stored already, except for the next rounds of a match
+	if s.store.Exists(ctx, g.ID) {
+		return s.store.UpdateGame(ctx, g)
+	}
 	return s.store.CreateGame(ctx, g)
 }
 
@@ -52,29 +81,99 @@ func (s *Service) WipeGameData(ctx context.Context, id uuid.UUID) error {
 	if err != nil {
 		return err
 	}
+	s.dropRoom(ctx, id)
+	s.deleteLog(ctx, id)
 	return s.store.DeleteGame(ctx, id)
 }
 
-// GetRoom ...
+// DropLobby ...
+func (s *Service) DropLobby(ctx context.Context, id uuid.UUID) error {
+	s.dropRoom(ctx, id)
+	s.deleteLog(ctx, id)
+	return s.store.DeleteGame(ctx, id)
+}
+
+// dropRoom removes a room that is not played anymore from this instance and from the hub.
+func (s *Service) dropRoom(ctx context.Context, roomID uuid.UUID) {
+	s.DeleteRoom(roomID)
+	s.releaseRoom(ctx, roomID)
+	if err := s.store.DeleteRoom(ctx, roomID); err != nil {
+		log.Err(err).Str("room", roomID.String()).Msg("failed to delete room")
+	}
+}
+
+// GetRoom returns a room run by this instance, a room that is not run by any instance is restored from the store.
+// Rooms owned by other instances are not returned, they are found with RoomOwner.
 func (s *Service) GetRoom(id uuid.UUID) (*game.Room, bool) {
 	if r, ok := s.localStorage.GetRoom(id); ok {
 		return r, ok
 	}
 
-	// does game exist in store?
-	if !s.store.Exists(context.Background(), id) {
+	ctx := context.Background()
+	// the rooms of matches play the game of their current round, the first round has the id of the room
+	m, gameID, err := s.store.LoadMatch(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("source", "hub").Msg("failed to load match")
+		return nil, false
+	}
+	if m == nil {
+		gameID = id
+	}
+
+	// does game exist in the log or in the store?
+	records, err := s.wal.Records(ctx, gameID)
+	if err != nil {
+		log.Error().Err(err).Str("source", "log").Msg("failed to read the log of the game, loading it from the hub")
+	}
+	if len(records) == 0 && !s.store.Exists(ctx, gameID) {
+		return nil, false
+	}
+	// the room is restored by the instance that claims it first
+	owned, err := s.claimRoom(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("source", "hub").Msg("failed to lease room")
+		return nil, false
+	}
+	if !owned {
 		return nil, false
 	}
 
 	// try to load game
-	g, err := s.store.LoadGame(context.Background(), id)
+	g, err := s.loadGame(ctx, gameID, records)
 	if err != nil {
 		log.Error().Err(err).Str("source", "hub").Msg("failed to load game")
+		s.releaseRoom(ctx, id)
 		return nil, false
 	}
+	// lobbies that were not started in time are not restored
+	if g.StartedAt == nil && time.Since(g.CreatedAt) >= g.Rules().LobbyDuration() {
+		s.releaseRoom(ctx, id)
+		s.deleteLog(ctx, gameID)
+		if err = s.store.DeleteGame(ctx, gameID); err != nil {
+			log.Error().Err(err).Str("source", "hub").Msg("failed to delete lobby")
+		}
+		if err = s.store.DeleteRoom(ctx, id); err != nil {
+			log.Error().Err(err).Str("source", "hub").Msg("failed to delete room")
+		}
+		return nil, false
+	}
+	// restore the events broadcast in the room, so that players can sync with the room
+	events, seq, err := s.store.LoadEvents(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("source", "hub").Msg("failed to load room events")
+	}
 	// create and add room to hub
-	r := game.NewRoom(g, s)
-	s.SetRoom(g.ID, r)
+	var r *game.Room
+	if m != nil {
+		r = game.RestoreMatch(m, g, events, seq, s)
+	} else {
+		r = game.RestoreRoom(g, events, seq, s)
+	}
+	// the game ended before the server stopped, the room finished storing it and there is no next round to play
+	if r.IsClosed() {
+		return nil, false
+	}
+	s.SetRoom(id, r)
 
 	return r, true
 }
@@ -85,36 +184,106 @@ func (s *Service) FinishGame(ctx context.Context, g *game.Game) error {
 	if err != nil {
 		return err
 	}
-	s.DeleteRoom(g.ID)
+	s.dropRoom(ctx, g.ID)
+	s.deleteLog(ctx, g.ID)
 	return s.store.DeleteGame(ctx, g.ID)
 }
 
-// ValidateWord ...
-func (s *Service) ValidateWord(word string) bool {
-	return s.wordGen.Validate(word)
+// FinishRound ...
+func (s *Service) FinishRound(ctx context.Context, m *game.Match, g *game.Game) error {
+	err := s.coldStorage.FinishGame(ctx, g)
+	if err != nil {
+		return err
+	}
+	if err = s.coldStorage.SaveRound(ctx, m, g); err != nil {
+		return err
+	}
+	if m.HasEnded() {
+		s.dropRoom(ctx, m.ID)
+	}
+	s.deleteLog(ctx, g.ID)
+	return s.store.DeleteGame(ctx, g.ID)
+}
+
+// ChangeOwner ...
+func (s *Service) ChangeOwner(ctx context.Context, g *game.Game) error {
+	return s.updateGame(ctx, g)
+}
+
+// SaveLobby ...
+func (s *Service) SaveLobby(ctx context.Context, g *game.Game) error {
+	return s.updateGame(ctx, g)
+}
+
+// SaveMatch ...
+func (s *Service) SaveMatch(ctx context.Context, m *game.Match, next *game.Game) error {
+	return s.store.SaveMatch(ctx, m, next.ID)
 }
 
-func (s *Service) AddGuess(ctx context.Context, gameID uuid.UUID, player string, guess word.Word, isBest bool) error {
-	return s.store.AddGuess(ctx, gameID, player, guess, isBest)
+// updateGame updates a game stored in the hub, the next rounds of a match are only stored once they have started.
+func (s *Service) updateGame(ctx context.Context, g *game.Game) error {
+	if !s.store.Exists(ctx, g.ID) {
+		return nil
+	}
+	return s.store.UpdateGame(ctx, g)
+}
+
+// AppendRecord ...
+func (s *Service) AppendRecord(ctx context.Context, gameID uuid.UUID, rec game.Record) error {
+	return s.wal.Append(ctx, gameID, rec)
+}
+
+// AddEvent ...
+func (s *Service) AddEvent(ctx context.Context, roomID uuid.UUID, event game.Payload) error {
+	return s.store.AddEvent(ctx, roomID, event)
+}
+
+// SaveSeq ...
+func (s *Service) SaveSeq(ctx context.Context, roomID uuid.UUID, seq uint64) error {
+	return s.store.SaveSeq(ctx, roomID, seq)
+}
+
+// GenerateWord ...
+func (s *Service) GenerateWord(length int) string {
+	wrd := s.wordGen.Generate(length)
+	log.Debug().Msg(wrd) // TODO: remove this on production, for now leave it for debugging
+	return wrd
 }
 
-// CreateInvite ...
-func (s *Service) CreateInvite(player game.Player, gameID uuid.UUID) string {
-	return s.r.Store(player, gameID)
+// ValidateWord ...
+func (s *Service) ValidateWord(word string) bool {
+	return s.wordGen.Validate(word)
 }
 
-// GetInviteData ...
-func (s *Service) GetInviteData(token string) (game.Player, uuid.UUID, bool) {
-	return s.r.Get(token)
+func (s *Service) AddGuess(ctx context.Context, gameID uuid.UUID, player string, guess word.Word, score float64, isBest bool) error {
+	return s.store.AddGuess(ctx, gameID, player, guess, score, isBest)
 }
 
 // New ...
-func New(appCtx context.Context, gr repository.Game, pr repository.Player, h repository.Hub) *Service {
-	return &Service{
-		r:            random.New(appCtx),
-		coldStorage:  newColdStorage(gr, pr),
+func New(appCtx context.Context, gr repository.Game, pr repository.Player, mr repository.Match, dr repository.Daily, h repository.Hub, ir repository.Invites, lr repository.Log, opts ...Option) *Service {
+	secret := config.Get("DAILY_SECRET")
+	if secret == "" {
+		log.Warn().Msg("DAILY_SECRET is not set, the words of the daily challenge can be predicted")
+	}
+	inviteSecret := config.Get("INVITE_SECRET")
+	if inviteSecret == "" {
+		log.Warn().Msg("INVITE_SECRET is not set, the stored invites can be used to join rooms")
+	}
+	s := &Service{
+		r:            random.New([]byte(inviteSecret)),
+		coldStorage:  newColdStorage(gr, pr, mr),
 		wordGen:      word.NewLocalGen(),
 		localStorage: newLocalStorage(appCtx),
 		store:        h,
+		invites:      ir,
+		wal:          lr,
+		instance:     config.Get("INSTANCE_URL"),
+		dr:           dr,
+		dailySecret:  []byte(secret),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	go s.renewLeases(appCtx)
+	return s
 }
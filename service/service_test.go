@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodekulture/wordle-server/game"
+	"github.com/kodekulture/wordle-server/game/word"
+	"github.com/kodekulture/wordle-server/repository"
+	"github.com/kodekulture/wordle-server/repository/file"
+	"github.com/kodekulture/wordle-server/repository/memory"
+)
+
+// fakeGames records the games finished by the rooms, the other methods of repository.Game are not used.
+type fakeGames struct {
+	repository.Game
+	finished chan *game.Game
+}
+
+func (f fakeGames) FinishGame(_ context.Context, g *game.Game) error {
+	if f.finished != nil {
+		f.finished <- g
+	}
+	return nil
+}
+
+// fakeMatches records the rounds stored by the rooms.
+type fakeMatches struct {
+	repository.Match
+	rounds chan game.Match
+}
+
+func (f fakeMatches) SaveRound(_ context.Context, m *game.Match, _ *game.Game) error {
+	f.rounds <- *m
+	return nil
+}
+
+// newService returns a service running as a single instance with the hub and the log of the test, unless `opts` set its instance.
+func newService(t *testing.T, hub repository.Hub, gr repository.Game, mr repository.Match, opts ...Option) *Service {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	wal, err := file.NewLog(t.TempDir())
+	require.NoError(t, err)
+	opts = append([]Option{WithInstance("")}, opts...)
+	return New(ctx, gr, nil, mr, nil, hub, memory.NewInvites(), wal, opts...)
+}
+
+func TestService_RestoreMatch(t *testing.T) {
+	ctx := context.Background()
+	hub := memory.NewHub()
+	matches := fakeMatches{rounds: make(chan game.Match, 4)}
+
+	first := game.New("fela", word.New("GAMES"))
+	first.Settings.Rounds = 3
+	first.Settings.TimeLimit = 60
+	first.Join(game.Player{Username: "fela"})
+	first.Join(game.Player{Username: "james"})
+	m := game.NewMatch(first)
+	m.Played = append(m.Played, game.Round{Number: 1, GameID: first.ID, CorrectWord: "GAMES"})
+	m.Scores = map[string]int{"fela": 2, "james": 0}
+
+	// the second round was being played when the server stopped, its time is up once it is restored
+	current := m.NextRound(first, word.New("WORLD"))
+	current.Start()
+	*current.StartedAt = time.Now().Add(-2 * time.Minute)
+	require.NoError(t, hub.CreateGame(ctx, current))
+	require.NoError(t, hub.SaveMatch(ctx, m, current.ID))
+
+	s := newService(t, hub, fakeGames{}, matches)
+	room, ok := s.GetRoom(first.ID)
+	require.True(t, ok)
+	t.Cleanup(room.Close)
+	assert.Equal(t, first.ID.String(), room.ID(), "the room keeps the id of the match")
+
+	select {
+	case round := <-matches.rounds:
+		require.Len(t, round.Played, 2, "the rounds played before the restore are kept")
+		assert.Equal(t, current.ID, round.Played[1].GameID)
+		assert.Equal(t, 2, round.Scores["fela"], "the scores played before the restore are kept")
+	case <-time.After(time.Second):
+		t.Fatal("the restored round did not end")
+	}
+	select {
+	case <-matches.rounds:
+		t.Fatal("the round was stored by more than one room")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	stored, next, err := hub.LoadMatch(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Len(t, stored.Played, 2)
+	assert.Equal(t, room.Game().ID, next, "the next round is stored under the id of the room")
+	restored, ok := s.GetRoom(first.ID)
+	require.True(t, ok)
+	assert.Same(t, room, restored)
+}
+
+func TestService_RestoreEnded(t *testing.T) {
+	ctx := context.Background()
+	hub := memory.NewHub()
+	games := fakeGames{finished: make(chan *game.Game, 1)}
+	s := newService(t, hub, games, nil)
+
+	// the server stopped after the end of the game was written to the log, before the game was stored
+	g := game.New("fela", word.New("GAMES"))
+	g.Settings.TimeLimit = 60
+	startedAt := time.Now().Add(-2 * time.Minute)
+	records := []game.Record{
+		game.CreateRecord(g),
+		{Kind: game.RecordJoin, At: startedAt, Player: &game.Player{Username: "fela", ID: 1}},
+		{Kind: game.RecordStart, At: startedAt},
+		{Kind: game.RecordGuess, At: startedAt, Username: "fela", Word: "GAMES"},
+		{Kind: game.RecordFinish, At: startedAt.Add(time.Second)},
+	}
+	for _, rec := range records {
+		require.NoError(t, s.wal.Append(ctx, g.ID, rec))
+	}
+
+	_, ok := s.GetRoom(g.ID)
+	assert.False(t, ok, "the room of an ended game is not restored")
+	select {
+	case finished := <-games.finished:
+		assert.Equal(t, g.ID, finished.ID)
+		assert.True(t, finished.Sessions["fela"].Won(), "the results of the game are stored")
+	case <-time.After(time.Second):
+		t.Fatal("the ended game was not stored")
+	}
+	got, err := s.wal.Records(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got, "the log of the game is deleted")
+	assert.False(t, hub.Exists(ctx, g.ID), "the game is deleted from the hub")
+}